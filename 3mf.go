@@ -0,0 +1,226 @@
+package meshview
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/fogleman/fauxgl"
+)
+
+const threeMFModelType = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+
+type threeMFRelationships struct {
+	Relationships []struct {
+		Target string `xml:"Target,attr"`
+		Type   string `xml:"Type,attr"`
+	} `xml:"Relationship"`
+}
+
+type threeMFModel struct {
+	Objects []threeMFObject    `xml:"resources>object"`
+	Items   []threeMFReference `xml:"build>item"`
+}
+
+type threeMFObject struct {
+	ID       int `xml:"id,attr"`
+	Vertices []struct {
+		X float64 `xml:"x,attr"`
+		Y float64 `xml:"y,attr"`
+		Z float64 `xml:"z,attr"`
+	} `xml:"mesh>vertices>vertex"`
+	Triangles []struct {
+		V1 int `xml:"v1,attr"`
+		V2 int `xml:"v2,attr"`
+		V3 int `xml:"v3,attr"`
+	} `xml:"mesh>triangles>triangle"`
+	Components []threeMFReference `xml:"components>component"`
+}
+
+// threeMFReference is a build item or component, referring to an object in
+// this model part or, with the production extension, in another part.
+type threeMFReference struct {
+	ObjectID  int    `xml:"objectid,attr"`
+	Transform string `xml:"transform,attr"`
+	Path      string `xml:"path,attr"`
+}
+
+type threeMFLoader struct {
+	files  map[string]*zip.File
+	models map[string]*threeMFModel
+	depth  int
+	data   []float32
+}
+
+func Load3MF(path string) (*MeshData, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return load3MF(&archive.Reader)
+}
+
+// Load3MFReader loads a 3mf package from r, which is read into memory first
+// since zip archives need random access.
+func Load3MFReader(r io.Reader) (*MeshData, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+	return load3MF(archive)
+}
+
+func load3MF(archive *zip.Reader) (*MeshData, error) {
+	loader := threeMFLoader{}
+	loader.files = make(map[string]*zip.File)
+	loader.models = make(map[string]*threeMFModel)
+	for _, file := range archive.File {
+		loader.files[strings.ToLower(file.Name)] = file
+	}
+
+	// find the root model part
+	root := "3D/3dmodel.model"
+	var rels threeMFRelationships
+	if err := loader.decode("_rels/.rels", &rels); err == nil {
+		for _, rel := range rels.Relationships {
+			if rel.Type == threeMFModelType {
+				root = rel.Target
+				break
+			}
+		}
+	}
+	model, err := loader.model(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// emit every object referenced by the build section
+	for _, item := range model.Items {
+		if err := loader.reference(root, item, fauxgl.Identity()); err != nil {
+			return nil, err
+		}
+	}
+	if len(loader.data) == 0 {
+		return nil, fmt.Errorf("3mf: no triangles")
+	}
+
+	box := boxForData(loader.data)
+	return &MeshData{Buffer: loader.data, Box: box}, nil
+}
+
+func (loader *threeMFLoader) decode(name string, v interface{}) error {
+	file, ok := loader.files[strings.ToLower(strings.TrimPrefix(name, "/"))]
+	if !ok {
+		return fmt.Errorf("3mf: missing part %s", name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err := xml.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("3mf: %s: %v", name, err)
+	}
+	return nil
+}
+
+func (loader *threeMFLoader) model(name string) (*threeMFModel, error) {
+	if model, ok := loader.models[name]; ok {
+		return model, nil
+	}
+	model := &threeMFModel{}
+	if err := loader.decode(name, model); err != nil {
+		return nil, err
+	}
+	loader.models[name] = model
+	return model, nil
+}
+
+func (loader *threeMFLoader) reference(part string, ref threeMFReference, parent fauxgl.Matrix) error {
+	if ref.Path != "" {
+		part = ref.Path
+	}
+	transform, err := parse3MFTransform(ref.Transform)
+	if err != nil {
+		return fmt.Errorf("3mf: object %d: %v", ref.ObjectID, err)
+	}
+	model, err := loader.model(part)
+	if err != nil {
+		return err
+	}
+	for i := range model.Objects {
+		if model.Objects[i].ID == ref.ObjectID {
+			return loader.object(part, &model.Objects[i], parent.Mul(transform))
+		}
+	}
+	return fmt.Errorf("3mf: %s: object %d not found", part, ref.ObjectID)
+}
+
+func (loader *threeMFLoader) object(part string, object *threeMFObject, matrix fauxgl.Matrix) error {
+	// guard against component cycles
+	if loader.depth > 64 {
+		return fmt.Errorf("3mf: object %d: components nested too deeply", object.ID)
+	}
+	loader.depth++
+	defer func() { loader.depth-- }()
+
+	// mirroring transforms flip the winding order
+	flip := matrix.Determinant() < 0
+	count := len(object.Vertices)
+	for _, t := range object.Triangles {
+		v1, v2, v3 := t.V1, t.V2, t.V3
+		if v1 < 0 || v1 >= count || v2 < 0 || v2 >= count || v3 < 0 || v3 >= count {
+			return fmt.Errorf("3mf: object %d: vertex index out of range", object.ID)
+		}
+		if flip {
+			v2, v3 = v3, v2
+		}
+		for _, j := range []int{v1, v2, v3} {
+			p := object.Vertices[j]
+			v := matrix.MulPosition(fauxgl.V(p.X, p.Y, p.Z))
+			loader.data = append(loader.data, float32(v.X), float32(v.Y), float32(v.Z))
+		}
+	}
+	for _, component := range object.Components {
+		if err := loader.reference(part, component, matrix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parse3MFTransform parses a 3mf affine transform, which lists the twelve
+// entries of a 4x3 matrix applied to row vectors.
+func parse3MFTransform(s string) (fauxgl.Matrix, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return fauxgl.Identity(), nil
+	}
+	if len(fields) != 12 {
+		return fauxgl.Matrix{}, fmt.Errorf("invalid transform: %s", s)
+	}
+	var m [12]float64
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return fauxgl.Matrix{}, fmt.Errorf("invalid transform: %s", s)
+		}
+		m[i] = value
+	}
+	return fauxgl.Matrix{
+		m[0], m[3], m[6], m[9],
+		m[1], m[4], m[7], m[10],
+		m[2], m[5], m[8], m[11],
+		0, 0, 0, 1,
+	}, nil
+}
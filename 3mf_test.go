@@ -0,0 +1,93 @@
+package meshview
+
+import (
+	"strings"
+	"testing"
+)
+
+// threeMFDocument is a model with a right triangle as object 1, a component
+// object 2 using it twice, and a build item for each, edited by pairs of old
+// and new strings.
+func threeMFDocument(pairs ...string) string {
+	return edit(`<?xml version="1.0" encoding="UTF-8"?>
+<model unit="millimeter" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+	<resources>
+		<object id="1" type="model">
+			<mesh>
+				<vertices>
+					<vertex x="0" y="0" z="0"/>
+					<vertex x="2" y="0" z="0"/>
+					<vertex x="0" y="3" z="0"/>
+				</vertices>
+				<triangles>
+					<triangle v1="0" v2="1" v3="2"/>
+				</triangles>
+			</mesh>
+		</object>
+		<object id="2" type="model">
+			<components>
+				<component objectid="1"/>
+				<component objectid="1" transform="1 0 0 0 1 0 0 0 1 0 0 5"/>
+			</components>
+		</object>
+	</resources>
+	<build>
+		<item objectid="1" transform="1 0 0 0 1 0 0 0 1 1 0 0"/>
+		<item objectid="2"/>
+	</build>
+</model>`, pairs...)
+}
+
+const threeMFRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+	<Relationship Target="/3D/other.model" Id="rel0" Type="http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"/>
+</Relationships>`
+
+func TestLoad3MF(t *testing.T) {
+	testLoader(t, Load3MFReader, []loaderTest{
+		{"build", zipFixture("3D/3dmodel.model", threeMFDocument()), 3},
+		{"relationships", zipFixture("_rels/.rels", threeMFRels, "3D/other.model", threeMFDocument()), 3},
+		{"case insensitive", zipFixture("3d/3DModel.model", threeMFDocument()), 3},
+		{"single item", zipFixture("3D/3dmodel.model", threeMFDocument(`<item objectid="2"/>`, "")), 1},
+		{"production path", zipFixture(
+			"3D/3dmodel.model", threeMFDocument(`<item objectid="2"/>`, `<item objectid="1" path="/3D/part.model"/>`),
+			"3D/part.model", threeMFDocument(`x="2"`, `x="4"`)), 2},
+		{"not a zip", "solid", loadFails},
+		{"missing model", zipFixture("3D/other.model", threeMFDocument()), loadFails},
+		{"missing relationship target", zipFixture("_rels/.rels", threeMFRels, "3D/3dmodel.model", threeMFDocument()), loadFails},
+		{"invalid xml", zipFixture("3D/3dmodel.model", threeMFDocument()[:200]), loadFails},
+		{"bad coordinate", zipFixture("3D/3dmodel.model", threeMFDocument(`x="2"`, `x="two"`)), loadFails},
+		{"object not found", zipFixture("3D/3dmodel.model", threeMFDocument(`<item objectid="2"/>`, `<item objectid="3"/>`)), loadFails},
+		{"index out of range", zipFixture("3D/3dmodel.model", threeMFDocument(`v3="2"`, `v3="3"`)), loadFails},
+		{"negative index", zipFixture("3D/3dmodel.model", threeMFDocument(`v1="0"`, `v1="-1"`)), loadFails},
+		{"short transform", zipFixture("3D/3dmodel.model", threeMFDocument(`transform="1 0 0 0 1 0 0 0 1 1 0 0"`, `transform="1 0 0"`)), loadFails},
+		{"bad transform", zipFixture("3D/3dmodel.model", threeMFDocument(`transform="1 0 0 0 1 0 0 0 1 1 0 0"`, `transform="1 0 0 0 1 0 0 0 1 x 0 0"`)), loadFails},
+		{"component cycle", zipFixture("3D/3dmodel.model", threeMFDocument(`<component objectid="1"/>`, `<component objectid="2"/>`)), loadFails},
+		{"missing part", zipFixture("3D/3dmodel.model", threeMFDocument(`<item objectid="2"/>`, `<item objectid="1" path="/3D/part.model"/>`)), loadFails},
+		{"no triangles", zipFixture("3D/3dmodel.model", threeMFDocument(`<item objectid="1" transform="1 0 0 0 1 0 0 0 1 1 0 0"/>`, "", `<item objectid="2"/>`, "")), loadFails},
+	})
+}
+
+func TestLoad3MFTransforms(t *testing.T) {
+	// the component placed at z 5 sets the top of the box, unless only the
+	// untransformed item is built
+	tests := []struct {
+		name  string
+		input string
+		maxZ  float64
+	}{
+		{"components", zipFixture("3D/3dmodel.model", threeMFDocument()), 5},
+		{"single item", zipFixture("3D/3dmodel.model", threeMFDocument(`<item objectid="2"/>`, "")), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := Load3MFReader(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if z := data.Box.Max.Z; z != test.maxZ {
+				t.Errorf("got max z %g, want %g", z, test.maxZ)
+			}
+		})
+	}
+}
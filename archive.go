@@ -0,0 +1,195 @@
+package meshview
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressed unpacks r according to ext and loads the mesh inside, using
+// name, the file name without the compression extension, to pick a format.
+func (loader *meshLoader) compressed(r io.Reader, ext, name string, resolve gltfResolver) (*MeshData, error) {
+	switch ext {
+	case ".gz":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		return loader.reader(gr, name, resolve)
+	case ".zst":
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return loader.reader(zr, name, resolve)
+	case ".zip":
+		// zip archives need random access
+		buf, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		archive, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+		if err != nil {
+			return nil, err
+		}
+		return loader.zip(archive, "")
+	}
+	return nil, fmt.Errorf("unrecognized compression: %s", ext)
+}
+
+// SplitZipPath splits a path like archive.zip/dir/model.stl into the archive
+// path and the entry name. The entry is empty for other paths.
+func SplitZipPath(path string) (string, string) {
+	i := strings.Index(strings.ToLower(filepath.ToSlash(path)), ".zip/")
+	if i < 0 {
+		return path, ""
+	}
+	return path[:i+4], filepath.ToSlash(path[i+5:])
+}
+
+// IsMeshName reports whether name has the extension of a mesh format,
+// optionally followed by a gzip or zstd extension.
+func IsMeshName(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".gz" || ext == ".zst" {
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(name, filepath.Ext(name))))
+	}
+	return meshExtensions[ext]
+}
+
+// ModelName returns the file name of the mesh at path without its format
+// and compression extensions, so that parts.zip/dir/model.stl.gz is model.
+func ModelName(path string) string {
+	archive, entry := SplitZipPath(path)
+	name := filepath.Base(archive)
+	if entry != "" {
+		name = filepath.Base(entry)
+	}
+	for compressedExtensions[strings.ToLower(filepath.Ext(name))] {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func (loader *meshLoader) zipFile(path, entry string) (*MeshData, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return loader.zip(&archive.Reader, entry)
+}
+
+// zip loads the named entry of the archive, or merges all of the mesh
+// entries if entry is empty. An archive that is a 3mf package is loaded as
+// one.
+func (loader *meshLoader) zip(archive *zip.Reader, entry string) (*MeshData, error) {
+	if entry != "" {
+		for _, file := range archive.File {
+			if file.Name == entry {
+				return loader.zipEntry(archive, file)
+			}
+		}
+		return nil, fmt.Errorf("zip: no entry named %s", entry)
+	}
+
+	for _, file := range archive.File {
+		if strings.EqualFold(filepath.Ext(file.Name), ".model") {
+			return load3MF(archive)
+		}
+	}
+
+	var meshes []*MeshData
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !IsMeshName(file.Name) {
+			continue
+		}
+		data, err := loader.zipEntry(archive, file)
+		if err != nil {
+			return nil, err
+		}
+		meshes = append(meshes, data)
+	}
+	if len(meshes) == 0 {
+		return nil, fmt.Errorf("zip: no mesh entries")
+	}
+	return mergeMeshData(meshes), nil
+}
+
+func (loader *meshLoader) zipEntry(archive *zip.Reader, file *zip.File) (*MeshData, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := loader.reader(contextReader{loader.ctx, reader}, file.Name, zipResolver(archive, file.Name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file.Name, err)
+	}
+	return data, nil
+}
+
+// zipResolver resolves the external buffers of the gltf entry name against
+// the other entries of archive.
+func zipResolver(archive *zip.Reader, name string) gltfResolver {
+	return func(uri string) ([]byte, error) {
+		target := path.Join(path.Dir(name), uri)
+		for _, file := range archive.File {
+			if file.Name == target {
+				reader, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer reader.Close()
+				return ioutil.ReadAll(reader)
+			}
+		}
+		return nil, fmt.Errorf("zip: no entry named %s", target)
+	}
+}
+
+// mergeMeshData combines meshes into one. The result is indexed only if all
+// of the meshes are, and has normals only if all of them do.
+func mergeMeshData(meshes []*MeshData) *MeshData {
+	if len(meshes) == 1 {
+		return meshes[0]
+	}
+	indexed := true
+	hasNormals := true
+	for _, data := range meshes {
+		indexed = indexed && data.Indices != nil
+		hasNormals = hasNormals && data.Normals != nil
+	}
+	result := &MeshData{}
+	for i, data := range meshes {
+		if i == 0 {
+			result.Box = data.Box
+		} else {
+			result.Box = result.Box.Extend(data.Box)
+		}
+		result.Warnings = append(result.Warnings, data.Warnings...)
+		if !indexed {
+			result.Buffer = append(result.Buffer, data.TriangleBuffer()...)
+			continue
+		}
+		offset := uint32(len(result.Buffer) / 3)
+		for _, index := range data.Indices {
+			result.Indices = append(result.Indices, index+offset)
+		}
+		result.Buffer = append(result.Buffer, data.Buffer...)
+		if hasNormals {
+			result.Normals = append(result.Normals, data.Normals...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package meshview
+
+import (
+	"bytes"
+	"compress/gzip"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipFixture(s string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.String()
+}
+
+func zstdFixture(s string) string {
+	var buf bytes.Buffer
+	w, _ := zstd.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.String()
+}
+
+func TestLoadMeshArchives(t *testing.T) {
+	// a gltf file with its buffer next to it in tri.bin
+	gltf := strings.Replace(gltfTriangle, "BUFFER", `"uri": "tri.bin", `, 1)
+	bin := string(gltfTriangleBuffer())
+	dir, cleanup := tempFiles(t,
+		"quad.stl.gz", gzipFixture(stlASCIIQuad),
+		"quad.ply.zst", zstdFixture(plyASCIIQuad),
+		"quad.gz", gzipFixture(objQuad),
+		"parts.zip", zipFixture("a/quad.stl", stlASCIIQuad, "b/quad.obj.gz", gzipFixture(objQuad), "readme.txt", "hello"),
+		"empty.zip", zipFixture("readme.txt", "hello"),
+		"gltf/tri.gltf.gz", gzipFixture(gltf),
+		"gltf/tri.bin", bin,
+		"gltf.zip", zipFixture("m/tri.gltf", gltf, "m/tri.bin", bin),
+		"nobuffer.zip", zipFixture("m/tri.gltf", gltf))
+	defer cleanup()
+	tests := []struct {
+		path      string
+		triangles int
+	}{
+		{"quad.stl.gz", 2},
+		{"quad.ply.zst", 2},
+		{"quad.gz", 2},
+		{"parts.zip", 4},
+		{"parts.zip/b/quad.obj.gz", 2},
+		{"parts.zip/c/quad.obj", loadFails},
+		{"empty.zip", loadFails},
+		{"gltf/tri.gltf.gz", 1},
+		{"gltf.zip", 1},
+		{"gltf.zip/m/tri.gltf", 1},
+		{"nobuffer.zip", loadFails},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			data, err := LoadMesh(filepath.Join(dir, filepath.FromSlash(test.path)))
+			if test.triangles == loadFails {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := data.TriangleCount(); n != test.triangles {
+				t.Errorf("got %d triangles, want %d", n, test.triangles)
+			}
+		})
+	}
+}
+
+func TestSplitZipPath(t *testing.T) {
+	tests := []struct {
+		path, archive, entry string
+	}{
+		{"model.stl", "model.stl", ""},
+		{"parts.zip", "parts.zip", ""},
+		{"parts.zip/model.stl", "parts.zip", "model.stl"},
+		{"dir/Parts.ZIP/a/b/model.stl", "dir/Parts.ZIP", "a/b/model.stl"},
+		{"parts.zipper/model.stl", "parts.zipper/model.stl", ""},
+	}
+	for _, test := range tests {
+		archive, entry := SplitZipPath(filepath.FromSlash(test.path))
+		if archive != filepath.FromSlash(test.archive) || entry != test.entry {
+			t.Errorf("%s: got %q %q, want %q %q", test.path, archive, entry, test.archive, test.entry)
+		}
+	}
+}
+
+func TestModelName(t *testing.T) {
+	tests := []struct {
+		path, name string
+	}{
+		{"dir/model.stl", "model"},
+		{"model.stl.gz", "model"},
+		{"parts.zip", "parts"},
+		{"parts.zip/dir/model.obj.zst", "model"},
+	}
+	for _, test := range tests {
+		if name := ModelName(filepath.FromSlash(test.path)); name != test.name {
+			t.Errorf("%s: got %q, want %q", test.path, name, test.name)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package meshview
+
+import (
+	"math"
+
+	"github.com/fogleman/fauxgl"
+)
+
+const bvhLeafSize = 4
+
+// BVH is a bounding volume hierarchy over the triangles of a MeshData, for
+// fast ray casting against large meshes.
+type BVH struct {
+	data      *MeshData
+	nodes     []bvhNode
+	triangles []int32
+}
+
+// bvhNode is a leaf if Count is nonzero, holding triangles[Index:Index+Count].
+// Otherwise its children are the next node and nodes[Index].
+type bvhNode struct {
+	Min, Max [3]float32
+	Index    int32
+	Count    int32
+}
+
+type bvhTriangle struct {
+	Min, Max, Center [3]float32
+}
+
+// NewBVH builds a BVH for data, which must not change afterwards. Building a
+// large mesh takes a while, so the viewer does it in the background.
+func NewBVH(data *MeshData) *BVH {
+	count := data.TriangleCount()
+	bounds := make([]bvhTriangle, count)
+	triangles := make([]int32, count)
+	parallel(count, func(i0, i1 int) {
+		for i := i0; i < i1; i++ {
+			p1, p2, p3 := data.Triangle(i)
+			min := p1.Min(p2).Min(p3)
+			max := p1.Max(p2).Max(p3)
+			b := &bounds[i]
+			b.Min = [3]float32{float32(min.X), float32(min.Y), float32(min.Z)}
+			b.Max = [3]float32{float32(max.X), float32(max.Y), float32(max.Z)}
+			for j := 0; j < 3; j++ {
+				b.Center[j] = (b.Min[j] + b.Max[j]) / 2
+			}
+			triangles[i] = int32(i)
+		}
+	})
+	bvh := &BVH{data: data, triangles: triangles}
+	if count > 0 {
+		bvh.nodes = make([]bvhNode, 0, count/bvhLeafSize*2+1)
+		bvh.build(bounds, 0, count)
+	}
+	return bvh
+}
+
+// build adds the node for triangles[start:end], splitting it at the middle
+// of the longest axis of the triangle centers, and returns its index.
+func (bvh *BVH) build(bounds []bvhTriangle, start, end int) int32 {
+	index := int32(len(bvh.nodes))
+	node := bvhNode{}
+	var lo, hi [3]float32
+	for j := 0; j < 3; j++ {
+		node.Min[j] = float32(math.Inf(1))
+		node.Max[j] = float32(math.Inf(-1))
+		lo[j] = node.Min[j]
+		hi[j] = node.Max[j]
+	}
+	for _, t := range bvh.triangles[start:end] {
+		b := &bounds[t]
+		for j := 0; j < 3; j++ {
+			node.Min[j] = min32(node.Min[j], b.Min[j])
+			node.Max[j] = max32(node.Max[j], b.Max[j])
+			lo[j] = min32(lo[j], b.Center[j])
+			hi[j] = max32(hi[j], b.Center[j])
+		}
+	}
+	bvh.nodes = append(bvh.nodes, node)
+	if end-start <= bvhLeafSize {
+		bvh.nodes[index].Index = int32(start)
+		bvh.nodes[index].Count = int32(end - start)
+		return index
+	}
+
+	// partition about the middle of the longest axis, or by count if all of
+	// the centers fall on one side
+	axis := 0
+	for j := 1; j < 3; j++ {
+		if hi[j]-lo[j] > hi[axis]-lo[axis] {
+			axis = j
+		}
+	}
+	split := (lo[axis] + hi[axis]) / 2
+	mid := start
+	for i := start; i < end; i++ {
+		t := bvh.triangles[i]
+		if bounds[t].Center[axis] < split {
+			bvh.triangles[i], bvh.triangles[mid] = bvh.triangles[mid], t
+			mid++
+		}
+	}
+	if mid == start || mid == end {
+		mid = (start + end) / 2
+	}
+
+	bvh.build(bounds, start, mid)
+	right := bvh.build(bounds, mid, end)
+	bvh.nodes[index].Index = right
+	return index
+}
+
+// Raycast returns the nearest triangle hit by the ray from origin along
+// direction. The distance is in units of direction, so it is in model units
+// when direction is normalized.
+func (bvh *BVH) Raycast(origin, direction fauxgl.Vector) (RayHit, bool) {
+	hit := RayHit{-1, math.Inf(1), 0, 0}
+	if len(bvh.nodes) == 0 {
+		return hit, false
+	}
+	o := [3]float64{origin.X, origin.Y, origin.Z}
+	d := [3]float64{direction.X, direction.Y, direction.Z}
+	stack := make([]int32, 1, 64)
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &bvh.nodes[i]
+		if _, ok := node.intersect(o, d, hit.Distance); !ok {
+			continue
+		}
+		if node.Count == 0 {
+			// visit the nearer child first
+			left, right := i+1, node.Index
+			t1, ok1 := bvh.nodes[left].intersect(o, d, hit.Distance)
+			t2, ok2 := bvh.nodes[right].intersect(o, d, hit.Distance)
+			if ok1 && ok2 && t1 < t2 {
+				stack = append(stack, right, left)
+			} else {
+				if ok1 {
+					stack = append(stack, left)
+				}
+				if ok2 {
+					stack = append(stack, right)
+				}
+			}
+			continue
+		}
+		for _, t := range bvh.triangles[node.Index : node.Index+node.Count] {
+			p1, p2, p3 := bvh.data.Triangle(int(t))
+			distance, u, v, ok := intersectTriangle(origin, direction, p1, p2, p3)
+			if ok && distance < hit.Distance {
+				hit = RayHit{int(t), distance, u, v}
+			}
+		}
+	}
+	return hit, hit.Triangle >= 0
+}
+
+// intersect returns the distance at which the ray enters the node's box, if
+// it does so before maxDistance.
+func (node *bvhNode) intersect(o, d [3]float64, maxDistance float64) (float64, bool) {
+	tmin, tmax := 0.0, maxDistance
+	for j := 0; j < 3; j++ {
+		lo, hi := float64(node.Min[j]), float64(node.Max[j])
+		if d[j] == 0 {
+			if o[j] < lo || o[j] > hi {
+				return 0, false
+			}
+			continue
+		}
+		t1 := (lo - o[j]) / d[j]
+		t2 := (hi - o[j]) / d[j]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin = math.Max(tmin, t1)
+		tmax = math.Min(tmax, t2)
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+package meshview
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/fogleman/fauxgl"
+)
+
+func TestBVHRaycast(t *testing.T) {
+	bvh := NewBVH(cubeMesh())
+	tests := []struct {
+		name      string
+		origin    fauxgl.Vector
+		direction fauxgl.Vector
+		hit       bool
+		distance  float64
+	}{
+		{"top", fauxgl.V(0.5, 0.25, 5), fauxgl.V(0, 0, -1), true, 4},
+		{"away", fauxgl.V(0.5, 0.25, 5), fauxgl.V(0, 0, 1), false, 0},
+		{"beside", fauxgl.V(5, 5, 5), fauxgl.V(0, 0, -1), false, 0},
+		{"inside", fauxgl.V(0, 0, 0), fauxgl.V(1, 0, 0), true, 1},
+		{"scaled direction", fauxgl.V(0.1, -3, 0.2), fauxgl.V(0, 2, 0), true, 1},
+		{"diagonal", fauxgl.V(3, 3, 3), fauxgl.V(-1, -1, -1), true, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hit, ok := bvh.Raycast(test.origin, test.direction)
+			if ok != test.hit {
+				t.Fatalf("got hit %v, want %v", ok, test.hit)
+			}
+			if ok && math.Abs(hit.Distance-test.distance) > 1e-9 {
+				t.Errorf("got distance %g, want %g", hit.Distance, test.distance)
+			}
+		})
+	}
+}
+
+func TestBVHMatchesRaycast(t *testing.T) {
+	// random triangles, so that the tree has many overlapping nodes
+	rnd := rand.New(rand.NewSource(1))
+	point := func(scale float64) fauxgl.Vector {
+		return fauxgl.V(rnd.Float64()*2-1, rnd.Float64()*2-1, rnd.Float64()*2-1).MulScalar(scale)
+	}
+	var buffer []float32
+	for i := 0; i < 1000; i++ {
+		center := point(1)
+		for j := 0; j < 3; j++ {
+			p := center.Add(point(0.1))
+			buffer = append(buffer, float32(p.X), float32(p.Y), float32(p.Z))
+		}
+	}
+	data := &MeshData{Buffer: buffer, Box: boxForData(buffer)}
+	bvh := NewBVH(data)
+
+	hits := 0
+	for i := 0; i < 1000; i++ {
+		origin := point(3)
+		direction := point(0.5).Sub(origin)
+		want, wantOK := data.Raycast(origin, direction)
+		got, gotOK := bvh.Raycast(origin, direction)
+		if gotOK != wantOK {
+			t.Fatalf("ray %d: got hit %v, want %v", i, gotOK, wantOK)
+		}
+		if !gotOK {
+			continue
+		}
+		hits++
+		// triangles may tie at shared points, so only distances must match
+		if math.Abs(got.Distance-want.Distance) > 1e-9 {
+			t.Errorf("ray %d: got distance %g, want %g", i, got.Distance, want.Distance)
+		}
+		p1, p2, p3 := data.Triangle(got.Triangle)
+		p := p1.Add(p2.Sub(p1).MulScalar(got.U)).Add(p3.Sub(p1).MulScalar(got.V))
+		if q := origin.Add(direction.MulScalar(got.Distance)); p.Sub(q).Length() > 1e-6 {
+			t.Errorf("ray %d: hit point %v is not on the ray at %v", i, p, q)
+		}
+	}
+	if hits == 0 {
+		t.Error("no rays hit the mesh")
+	}
+}
+
+func TestBVHEmpty(t *testing.T) {
+	bvh := NewBVH(&MeshData{})
+	if _, ok := bvh.Raycast(fauxgl.V(0, 0, 5), fauxgl.V(0, 0, -1)); ok {
+		t.Error("got a hit on an empty mesh")
+	}
+}
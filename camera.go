@@ -0,0 +1,56 @@
+package meshview
+
+import (
+	"math"
+
+	"github.com/fogleman/fauxgl"
+)
+
+// PresetView returns the rotation for one of the numbered preset views, 1
+// through 7, which the viewer binds to the number keys.
+func PresetView(view int) fauxgl.Matrix {
+	switch view {
+	case 2:
+		return fauxgl.Identity().Rotate(fauxgl.V(0, 0, 1), math.Pi/2)
+	case 3:
+		return fauxgl.Identity().Rotate(fauxgl.V(0, 0, 1), math.Pi)
+	case 4:
+		return fauxgl.Identity().Rotate(fauxgl.V(0, 0, 1), -math.Pi/2)
+	case 5:
+		return fauxgl.Identity().Rotate(fauxgl.V(1, 0, 0), math.Pi/2)
+	case 6:
+		return fauxgl.Identity().Rotate(fauxgl.V(1, 0, 0), -math.Pi/2)
+	case 7:
+		return fauxgl.Identity().Rotate(fauxgl.V(1, 1, 0).Normalize(), -math.Pi/4).Rotate(fauxgl.V(0, 0, 1), math.Pi/4)
+	}
+	return fauxgl.Identity()
+}
+
+// Camera scales by scroll and rotates by r about the pivot, then translates
+// by t and applies the perspective or orthographic camera. It is the view of
+// the viewer's arcball and turntable modes, and of Render.
+func Camera(r fauxgl.Matrix, pivot, t fauxgl.Vector, scroll, aspect float64, ortho bool) fauxgl.Matrix {
+	s := ZoomScale(scroll)
+	m := fauxgl.Translate(pivot.Negate())
+	m = m.Scale(fauxgl.V(s, s, s))
+	m = r.Mul(m)
+	m = m.Translate(t)
+	m = m.LookAt(fauxgl.V(0, -3, 0), fauxgl.V(0, 0, 0), fauxgl.V(0, 0, 1))
+	return Projection(m, 3, aspect, 0.1, ortho)
+}
+
+// ZoomScale returns the scale Camera applies for scroll.
+func ZoomScale(scroll float64) float64 {
+	return math.Pow(0.98, scroll)
+}
+
+// Projection applies the perspective projection, or an orthographic one
+// showing things at the given distance from the camera at the same size.
+func Projection(m fauxgl.Matrix, distance, aspect, near float64, ortho bool) fauxgl.Matrix {
+	const fovy = 50
+	if !ortho {
+		return m.Perspective(fovy, aspect, near, 100)
+	}
+	h := distance * math.Tan(fauxgl.Radians(fovy/2))
+	return m.Orthographic(-h*aspect, h*aspect, -h, h, -100, 100)
+}
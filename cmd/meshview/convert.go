@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fogleman/meshview"
+)
+
+func convert(args []string) {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	ascii := flags.Bool("ascii", false, "write ascii instead of binary stl or ply")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: meshview convert [flags] input output")
+		fmt.Fprintln(os.Stderr, "the output format is chosen by extension: .stl, .obj or .ply")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	data := loadMesh(flags.Arg(0))
+	if err := meshview.SaveMesh(flags.Arg(1), data, *ascii); err != nil {
+		fatal(err)
+	}
+}
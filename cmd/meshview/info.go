@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fogleman/meshview"
+)
+
+func info(args []string) {
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print statistics as json")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: meshview info [flags] model...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var results []infoResult
+	for _, path := range flags.Args() {
+		data := loadMesh(path)
+		r := newInfoResult(path, meshview.NewMeshInfo(data))
+		if *asJSON {
+			results = append(results, r)
+			continue
+		}
+		printInfo(os.Stdout, r)
+	}
+
+	if *asJSON {
+		if err := printInfoJSON(os.Stdout, results); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// infoResult is the statistics info prints for one model.
+type infoResult struct {
+	Path             string     `json:"path"`
+	Triangles        int        `json:"triangles"`
+	Vertices         int        `json:"vertices"`
+	Min              [3]float64 `json:"min"`
+	Max              [3]float64 `json:"max"`
+	Size             [3]float64 `json:"size"`
+	Area             float64    `json:"area"`
+	Volume           float64    `json:"volume"`
+	Components       int        `json:"components"`
+	BoundaryEdges    int        `json:"boundary_edges"`
+	NonManifoldEdges int        `json:"non_manifold_edges"`
+}
+
+func newInfoResult(path string, mi *meshview.MeshInfo) infoResult {
+	min := mi.Box.Min
+	max := mi.Box.Max
+	size := mi.Box.Size()
+	return infoResult{
+		path, mi.Triangles, mi.Vertices,
+		[3]float64{min.X, min.Y, min.Z},
+		[3]float64{max.X, max.Y, max.Z},
+		[3]float64{size.X, size.Y, size.Z},
+		mi.Area, mi.Volume, mi.Components,
+		mi.BoundaryEdges, mi.NonManifoldEdges,
+	}
+}
+
+func printInfo(w io.Writer, r infoResult) {
+	fmt.Fprintln(w, r.Path)
+	fmt.Fprintf(w, "  triangles:          %d\n", r.Triangles)
+	fmt.Fprintf(w, "  vertices:           %d\n", r.Vertices)
+	fmt.Fprintf(w, "  min:                %g %g %g\n", r.Min[0], r.Min[1], r.Min[2])
+	fmt.Fprintf(w, "  max:                %g %g %g\n", r.Max[0], r.Max[1], r.Max[2])
+	fmt.Fprintf(w, "  size:               %g %g %g\n", r.Size[0], r.Size[1], r.Size[2])
+	fmt.Fprintf(w, "  surface area:       %g\n", r.Area)
+	fmt.Fprintf(w, "  signed volume:      %g\n", r.Volume)
+	fmt.Fprintf(w, "  components:         %d\n", r.Components)
+	fmt.Fprintf(w, "  boundary edges:     %d\n", r.BoundaryEdges)
+	fmt.Fprintf(w, "  non-manifold edges: %d\n", r.NonManifoldEdges)
+}
+
+// printInfoJSON prints one object for a single model, or an array of them.
+func printInfoJSON(w io.Writer, results []infoResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if len(results) == 1 {
+		return encoder.Encode(results[0])
+	}
+	return encoder.Encode(results)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
+)
+
+func cubeInfo() infoResult {
+	box := fauxgl.Box{fauxgl.V(-1, -1, -1), fauxgl.V(1, 1, 1)}
+	return newInfoResult("cube.stl", &meshview.MeshInfo{
+		Triangles: 12, Vertices: 8, Box: box, Area: 24, Volume: 8, Components: 1,
+	})
+}
+
+func TestPrintInfo(t *testing.T) {
+	var buf bytes.Buffer
+	printInfo(&buf, cubeInfo())
+	want := `cube.stl
+  triangles:          12
+  vertices:           8
+  min:                -1 -1 -1
+  max:                1 1 1
+  size:               2 2 2
+  surface area:       24
+  signed volume:      8
+  components:         1
+  boundary edges:     0
+  non-manifold edges: 0
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrintInfoJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printInfoJSON(&buf, []infoResult{cubeInfo()}); err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result["path"] != "cube.stl" || result["volume"] != 8.0 || result["boundary_edges"] != 0.0 {
+		t.Errorf("got %v", result)
+	}
+
+	// more models print an array
+	buf.Reset()
+	if err := printInfoJSON(&buf, []infoResult{cubeInfo(), cubeInfo()}); err != nil {
+		t.Fatal(err)
+	}
+	var results []infoResult
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil || len(results) != 2 {
+		t.Errorf("got %v, %v, want two results", results, err)
+	}
+}
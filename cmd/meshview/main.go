@@ -1,16 +1,52 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"os"
 
 	"github.com/fogleman/meshview"
 )
 
+// loadOptions is set by the flags and used for every mesh loaded, by the
+// viewer as well as the other commands
+var loadOptions = meshview.LoadOptions{Warnings: os.Stderr}
+
 func main() {
-	args := os.Args[1:]
+	flag.Float64Var(&loadOptions.WeldEpsilon, "weld", 0,
+		"merge stl vertices closer than this distance, or -1 to disable welding")
+	flag.BoolVar(&loadOptions.Lenient, "lenient", false,
+		"skip malformed records in text formats instead of failing")
+	view := viewFlags()
+	flag.Parse()
+	args := flag.Args()
 	if len(args) > 0 {
-		meshview.Run(args[0])
-	} else {
-		meshview.Run("")
+		switch args[0] {
+		case "render":
+			render(args[1:])
+			return
+		case "info":
+			info(args[1:])
+			return
+		case "convert":
+			convert(args[1:])
+			return
+		}
+	}
+	if err := view(args); err != nil {
+		fatal(err)
 	}
 }
+
+func loadMesh(path string) *meshview.MeshData {
+	data, err := meshview.LoadMeshOptions(path, &loadOptions)
+	if err != nil {
+		fatal(err)
+	}
+	return data
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "meshview:", err)
+	os.Exit(1)
+}
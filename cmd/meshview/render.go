@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fogleman/meshview"
+)
+
+func render(args []string) {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	view := flags.Int("view", 1, "preset view, 1-7, as bound to the number keys")
+	width := flags.Int("width", 640, "image width in pixels")
+	height := flags.Int("height", 640, "image height in pixels")
+	supersample := flags.Int("aa", 4, "supersampling factor for antialiasing")
+	output := flags.String("o", "", "output path (default: input path with .png extension)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: meshview render [flags] model")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *view < 1 || *view > 7 {
+		fatal(fmt.Errorf("invalid view: %d", *view))
+	}
+	if *width < 1 || *height < 1 {
+		fatal(fmt.Errorf("invalid size: %dx%d", *width, *height))
+	}
+
+	path := flags.Arg(0)
+	if *output == "" {
+		if path == "-" {
+			fatal(fmt.Errorf("-o is required when reading from stdin"))
+		}
+		*output = strings.TrimSuffix(path, filepath.Ext(path)) + ".png"
+	}
+
+	data := loadMesh(path)
+	im := meshview.Render(data, *view, *width, *height, *supersample)
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fatal(err)
+	}
+	if err := png.Encode(file, im); err != nil {
+		file.Close()
+		fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		fatal(err)
+	}
+}
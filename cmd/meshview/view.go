@@ -0,0 +1,30 @@
+//go:build !nogl
+// +build !nogl
+
+package main
+
+import (
+	"flag"
+
+	"github.com/fogleman/meshview/viewer"
+)
+
+// viewFlags registers the viewer's flags and returns the function that opens
+// the window.
+func viewFlags() func(paths []string) error {
+	options := viewer.DefaultOptions()
+	flag.Float64Var(&options.CreaseAngle, "crease", options.CreaseAngle,
+		"angle in degrees above which edges stay sharp with smooth shading")
+	flag.BoolVar(&options.WatchDirectory, "watchdir", false,
+		"list a browsed directory again when files are added or removed")
+	flag.StringVar(&options.ScreenshotDir, "shotdir", "",
+		"directory for screenshots (default: next to the model)")
+	flag.IntVar(&options.ScreenshotScale, "shotscale", options.ScreenshotScale,
+		"capture screenshots at this many times the window size")
+	flag.BoolVar(&options.ScreenshotTransparent, "transparent", false,
+		"capture screenshots with a transparent background")
+	return func(paths []string) error {
+		options.Load = loadOptions
+		return viewer.Run(options, paths...)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build nogl
+// +build nogl
+
+package main
+
+import "errors"
+
+// viewFlags returns a function that fails, since this binary was built with
+// the nogl tag and has no viewer. The render, info and convert commands
+// still work.
+func viewFlags() func(paths []string) error {
+	return func(paths []string) error {
+		return errors.New("built without the viewer (nogl); use render, info or convert")
+	}
+}
@@ -0,0 +1,492 @@
+package meshview
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/fogleman/fauxgl"
+)
+
+const (
+	glbMagic     = 0x46546C67
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+const gltfTriangles = 4
+
+var gltfModes = []string{
+	"POINTS", "LINES", "LINE_LOOP", "LINE_STRIP",
+	"TRIANGLES", "TRIANGLE_STRIP", "TRIANGLE_FAN",
+}
+
+var gltfComponentSizes = map[int]int{
+	5120: 1, 5121: 1, 5122: 2, 5123: 2, 5125: 4, 5126: 4,
+}
+
+var gltfTypeSizes = map[string]int{
+	"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4,
+	"MAT2": 4, "MAT3": 9, "MAT4": 16,
+}
+
+type gltfDocument struct {
+	Scene  *int
+	Scenes []struct {
+		Nodes []int
+	}
+	Nodes []struct {
+		Mesh        *int
+		Children    []int
+		Matrix      []float64
+		Translation []float64
+		Rotation    []float64
+		Scale       []float64
+	}
+	Meshes []struct {
+		Primitives []struct {
+			Attributes map[string]int
+			Indices    *int
+			Mode       *int
+		}
+	}
+	Accessors []struct {
+		BufferView    *int
+		ByteOffset    int
+		ComponentType int
+		Normalized    bool
+		Count         int
+		Type          string
+		Sparse        *json.RawMessage
+	}
+	BufferViews []struct {
+		Buffer     int
+		ByteOffset int
+		ByteLength int
+		ByteStride int
+	}
+	Buffers []struct {
+		URI        string
+		ByteLength int
+	}
+	ExtensionsRequired []string
+}
+
+// gltfResolver reads an external buffer, given its path relative to the gltf
+// file.
+type gltfResolver func(name string) ([]byte, error)
+
+// gltfDir resolves external buffers against a directory.
+func gltfDir(dir string) gltfResolver {
+	return func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+}
+
+func LoadGLTF(path string) (*MeshData, error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return loadGLTF(file, gltfDir(filepath.Dir(path)))
+}
+
+// LoadGLTFReader loads gltf or glb data from r. External buffers are
+// resolved relative to the current directory.
+func LoadGLTFReader(r io.Reader) (*MeshData, error) {
+	return loadGLTFReader(r, gltfDir("."))
+}
+
+func loadGLTFReader(r io.Reader, resolve gltfResolver) (*MeshData, error) {
+	file, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return loadGLTF(file, resolve)
+}
+
+func loadGLTF(file []byte, resolve gltfResolver) (*MeshData, error) {
+	var err error
+
+	// split binary container into json and bin chunks
+	var doc gltfDocument
+	var bin []byte
+	if len(file) >= 12 && binary.LittleEndian.Uint32(file) == glbMagic {
+		var chunk []byte
+		chunk, bin, err = parseGLB(file)
+		if err != nil {
+			return nil, err
+		}
+		file = chunk
+	}
+	if err := json.Unmarshal(file, &doc); err != nil {
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+	for _, name := range doc.ExtensionsRequired {
+		if !gltfSupportedExtension(name) {
+			return nil, fmt.Errorf("gltf: unsupported required extension %s", name)
+		}
+	}
+
+	// load buffers
+	buffers := make([][]byte, len(doc.Buffers))
+	for i, b := range doc.Buffers {
+		switch {
+		case b.URI == "":
+			if i != 0 || bin == nil {
+				return nil, fmt.Errorf("gltf: buffer %d has no data", i)
+			}
+			buffers[i] = bin
+		case strings.HasPrefix(b.URI, "data:"):
+			j := strings.Index(b.URI, ";base64,")
+			if j < 0 {
+				return nil, fmt.Errorf("gltf: buffer %d: unsupported data uri", i)
+			}
+			buffers[i], err = base64.StdEncoding.DecodeString(b.URI[j+8:])
+			if err != nil {
+				return nil, fmt.Errorf("gltf: buffer %d: %v", i, err)
+			}
+		default:
+			name, err := url.PathUnescape(b.URI)
+			if err != nil {
+				name = b.URI
+			}
+			buffers[i], err = resolve(name)
+			if err != nil {
+				return nil, fmt.Errorf("gltf: buffer %d: %v", i, err)
+			}
+		}
+		if len(buffers[i]) < b.ByteLength {
+			return nil, fmt.Errorf("gltf: buffer %d is truncated", i)
+		}
+	}
+
+	// find root nodes
+	var roots []int
+	if len(doc.Scenes) > 0 {
+		scene := 0
+		if doc.Scene != nil {
+			scene = *doc.Scene
+		}
+		if scene < 0 || scene >= len(doc.Scenes) {
+			return nil, fmt.Errorf("gltf: scene %d out of range", scene)
+		}
+		roots = doc.Scenes[scene].Nodes
+	} else {
+		isChild := make([]bool, len(doc.Nodes))
+		for _, node := range doc.Nodes {
+			for _, child := range node.Children {
+				if child >= 0 && child < len(isChild) {
+					isChild[child] = true
+				}
+			}
+		}
+		for i := range doc.Nodes {
+			if !isChild[i] {
+				roots = append(roots, i)
+			}
+		}
+	}
+
+	// walk node hierarchy, emitting transformed triangles
+	var data []float32
+	visited := make([]bool, len(doc.Nodes))
+	var walk func(index int, parent fauxgl.Matrix) error
+	walk = func(index int, parent fauxgl.Matrix) error {
+		if index < 0 || index >= len(doc.Nodes) {
+			return fmt.Errorf("gltf: node %d out of range", index)
+		}
+		if visited[index] {
+			return fmt.Errorf("gltf: node %d appears more than once in hierarchy", index)
+		}
+		visited[index] = true
+		node := doc.Nodes[index]
+		matrix, err := gltfNodeMatrix(node.Matrix, node.Translation, node.Rotation, node.Scale)
+		if err != nil {
+			return fmt.Errorf("gltf: node %d: %v", index, err)
+		}
+		matrix = parent.Mul(matrix)
+		if node.Mesh != nil {
+			data, err = appendGLTFMesh(data, &doc, buffers, *node.Mesh, matrix)
+			if err != nil {
+				return err
+			}
+		}
+		for _, child := range node.Children {
+			if err := walk(child, matrix); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, root := range roots {
+		if err := walk(root, fauxgl.Identity()); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("gltf: no triangles")
+	}
+
+	box := boxForData(data)
+	return &MeshData{Buffer: data, Box: box}, nil
+}
+
+func parseGLB(file []byte) ([]byte, []byte, error) {
+	version := binary.LittleEndian.Uint32(file[4:])
+	if version != 2 {
+		return nil, nil, fmt.Errorf("glb: unsupported version %d", version)
+	}
+	length := int(binary.LittleEndian.Uint32(file[8:]))
+	if length > len(file) {
+		return nil, nil, fmt.Errorf("glb: truncated file")
+	}
+	var chunkJSON, chunkBIN []byte
+	for offset := 12; offset+8 <= length; {
+		size := int(binary.LittleEndian.Uint32(file[offset:]))
+		kind := binary.LittleEndian.Uint32(file[offset+4:])
+		offset += 8
+		if size < 0 || offset+size > length {
+			return nil, nil, fmt.Errorf("glb: truncated chunk")
+		}
+		switch kind {
+		case glbChunkJSON:
+			chunkJSON = file[offset : offset+size]
+		case glbChunkBIN:
+			if chunkBIN == nil {
+				chunkBIN = file[offset : offset+size]
+			}
+		}
+		offset += size
+	}
+	if chunkJSON == nil {
+		return nil, nil, fmt.Errorf("glb: missing json chunk")
+	}
+	return chunkJSON, chunkBIN, nil
+}
+
+// gltfSupportedExtension reports whether a required extension is handled or
+// can be ignored. Materials and textures are not drawn, and quantized
+// attributes are read like any others, but compressed geometry, like Draco or
+// meshopt, is not supported.
+func gltfSupportedExtension(name string) bool {
+	return name == "KHR_mesh_quantization" ||
+		strings.HasPrefix(name, "KHR_materials_") ||
+		strings.HasPrefix(name, "KHR_texture_")
+}
+
+func gltfNodeMatrix(m, t, r, s []float64) (fauxgl.Matrix, error) {
+	if len(m) > 0 {
+		if len(m) != 16 {
+			return fauxgl.Matrix{}, fmt.Errorf("matrix must have 16 elements")
+		}
+		// gltf matrices are column major
+		return fauxgl.Matrix{
+			m[0], m[4], m[8], m[12],
+			m[1], m[5], m[9], m[13],
+			m[2], m[6], m[10], m[14],
+			m[3], m[7], m[11], m[15],
+		}, nil
+	}
+	matrix := fauxgl.Identity()
+	if len(s) > 0 {
+		if len(s) != 3 {
+			return fauxgl.Matrix{}, fmt.Errorf("scale must have 3 elements")
+		}
+		matrix = fauxgl.Scale(fauxgl.V(s[0], s[1], s[2]))
+	}
+	if len(r) > 0 {
+		if len(r) != 4 {
+			return fauxgl.Matrix{}, fmt.Errorf("rotation must have 4 elements")
+		}
+		matrix = quaternionMatrix(r[0], r[1], r[2], r[3]).Mul(matrix)
+	}
+	if len(t) > 0 {
+		if len(t) != 3 {
+			return fauxgl.Matrix{}, fmt.Errorf("translation must have 3 elements")
+		}
+		matrix = fauxgl.Translate(fauxgl.V(t[0], t[1], t[2])).Mul(matrix)
+	}
+	return matrix, nil
+}
+
+func quaternionMatrix(x, y, z, w float64) fauxgl.Matrix {
+	n := math.Sqrt(x*x + y*y + z*z + w*w)
+	if n == 0 {
+		return fauxgl.Identity()
+	}
+	x, y, z, w = x/n, y/n, z/n, w/n
+	return fauxgl.Matrix{
+		1 - 2*(y*y+z*z), 2 * (x*y - z*w), 2 * (x*z + y*w), 0,
+		2 * (x*y + z*w), 1 - 2*(x*x+z*z), 2 * (y*z - x*w), 0,
+		2 * (x*z - y*w), 2 * (y*z + x*w), 1 - 2*(x*x+y*y), 0,
+		0, 0, 0, 1,
+	}
+}
+
+func appendGLTFMesh(data []float32, doc *gltfDocument, buffers [][]byte, index int, matrix fauxgl.Matrix) ([]float32, error) {
+	if index < 0 || index >= len(doc.Meshes) {
+		return nil, fmt.Errorf("gltf: mesh %d out of range", index)
+	}
+	// mirroring transforms flip the winding order
+	flip := matrix.Determinant() < 0
+	for pi, p := range doc.Meshes[index].Primitives {
+		mode := gltfTriangles
+		if p.Mode != nil {
+			mode = *p.Mode
+		}
+		if mode != gltfTriangles {
+			name := fmt.Sprint(mode)
+			if mode >= 0 && mode < len(gltfModes) {
+				name = gltfModes[mode]
+			}
+			return nil, fmt.Errorf("gltf: mesh %d primitive %d: unsupported primitive mode %s", index, pi, name)
+		}
+		accessor, ok := p.Attributes["POSITION"]
+		if !ok {
+			return nil, fmt.Errorf("gltf: mesh %d primitive %d: missing POSITION attribute", index, pi)
+		}
+		positions, err := readGLTFAccessor(doc, buffers, accessor, "VEC3")
+		if err != nil {
+			return nil, err
+		}
+		count := len(positions) / 3
+		var indices []int
+		if p.Indices != nil {
+			values, err := readGLTFAccessor(doc, buffers, *p.Indices, "SCALAR")
+			if err != nil {
+				return nil, err
+			}
+			indices = make([]int, len(values))
+			for i, value := range values {
+				indices[i] = int(value)
+				if indices[i] < 0 || indices[i] >= count {
+					return nil, fmt.Errorf("gltf: mesh %d primitive %d: index %d out of range", index, pi, indices[i])
+				}
+			}
+		} else {
+			indices = make([]int, count)
+			for i := range indices {
+				indices[i] = i
+			}
+		}
+		for i := 0; i+2 < len(indices); i += 3 {
+			i1, i2, i3 := indices[i], indices[i+1], indices[i+2]
+			if flip {
+				i2, i3 = i3, i2
+			}
+			for _, j := range []int{i1, i2, i3} {
+				v := fauxgl.V(positions[j*3], positions[j*3+1], positions[j*3+2])
+				v = matrix.MulPosition(v)
+				data = append(data, float32(v.X), float32(v.Y), float32(v.Z))
+			}
+		}
+	}
+	return data, nil
+}
+
+func readGLTFAccessor(doc *gltfDocument, buffers [][]byte, index int, expectedType string) ([]float64, error) {
+	if index < 0 || index >= len(doc.Accessors) {
+		return nil, fmt.Errorf("gltf: accessor %d out of range", index)
+	}
+	a := doc.Accessors[index]
+	if a.Type != expectedType {
+		return nil, fmt.Errorf("gltf: accessor %d: expected %s, got %s", index, expectedType, a.Type)
+	}
+	if a.Sparse != nil {
+		return nil, fmt.Errorf("gltf: accessor %d: sparse accessors are not supported", index)
+	}
+	componentSize, ok := gltfComponentSizes[a.ComponentType]
+	if !ok {
+		return nil, fmt.Errorf("gltf: accessor %d: invalid component type %d", index, a.ComponentType)
+	}
+	if a.BufferView == nil {
+		// the data would be all zeros, or comes from an extension for
+		// compressed geometry
+		return nil, fmt.Errorf("gltf: accessor %d has no buffer view", index)
+	}
+	if *a.BufferView < 0 || *a.BufferView >= len(doc.BufferViews) {
+		return nil, fmt.Errorf("gltf: accessor %d: buffer view %d out of range", index, *a.BufferView)
+	}
+	view := doc.BufferViews[*a.BufferView]
+	if view.Buffer < 0 || view.Buffer >= len(buffers) {
+		return nil, fmt.Errorf("gltf: accessor %d: buffer %d out of range", index, view.Buffer)
+	}
+	buffer := buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset > len(buffer) || view.ByteLength > len(buffer)-view.ByteOffset {
+		return nil, fmt.Errorf("gltf: accessor %d: buffer view exceeds buffer", index)
+	}
+	buffer = buffer[view.ByteOffset : view.ByteOffset+view.ByteLength]
+	n := gltfTypeSizes[a.Type]
+	elementSize := componentSize * n
+	stride := elementSize
+	if view.ByteStride != 0 {
+		if view.ByteStride < elementSize {
+			return nil, fmt.Errorf("gltf: accessor %d: invalid byte stride %d", index, view.ByteStride)
+		}
+		stride = view.ByteStride
+	}
+	if a.ByteOffset < 0 || a.Count < 0 {
+		return nil, fmt.Errorf("gltf: accessor %d: invalid offset or count", index)
+	}
+	// compare counts rather than byte offsets, which could overflow
+	if a.Count > 0 {
+		last := len(buffer) - a.ByteOffset - elementSize
+		if last < 0 || a.Count-1 > last/stride {
+			return nil, fmt.Errorf("gltf: accessor %d exceeds buffer view", index)
+		}
+	}
+	result := make([]float64, a.Count*n)
+	for i := 0; i < a.Count; i++ {
+		b := buffer[a.ByteOffset+i*stride:]
+		for j := 0; j < n; j++ {
+			c := b[j*componentSize:]
+			var value float64
+			switch a.ComponentType {
+			case 5120:
+				value = float64(int8(c[0]))
+			case 5121:
+				value = float64(c[0])
+			case 5122:
+				value = float64(int16(binary.LittleEndian.Uint16(c)))
+			case 5123:
+				value = float64(binary.LittleEndian.Uint16(c))
+			case 5125:
+				value = float64(binary.LittleEndian.Uint32(c))
+			case 5126:
+				value = float64(math.Float32frombits(binary.LittleEndian.Uint32(c)))
+			}
+			result[i*n+j] = value
+		}
+	}
+	if a.Normalized {
+		normalizeGLTF(result, a.ComponentType)
+	}
+	return result, nil
+}
+
+func normalizeGLTF(values []float64, componentType int) {
+	var d float64
+	switch componentType {
+	case 5120:
+		d = 127
+	case 5121:
+		d = 255
+	case 5122:
+		d = 32767
+	case 5123:
+		d = 65535
+	default:
+		return
+	}
+	for i, v := range values {
+		values[i] = math.Max(v/d, -1)
+	}
+}
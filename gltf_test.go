@@ -0,0 +1,121 @@
+package meshview
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// gltfTriangleBuffer holds the three float positions of a right triangle
+// followed by three uint16 indices.
+func gltfTriangleBuffer() []byte {
+	return []byte(pack(binary.LittleEndian, []float32{0, 0, 0, 2, 0, 0, 0, 3, 0}, []uint16{0, 1, 2}))
+}
+
+// gltfTriangle is a document drawing the triangle of gltfTriangleBuffer,
+// translated by 1 along x. BUFFER stands for the buffer uri.
+const gltfTriangle = `{
+	"asset": {"version": "2.0"},
+	"scene": 0,
+	"scenes": [{"nodes": [0]}],
+	"nodes": [{"mesh": 0, "translation": [1, 0, 0]}],
+	"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1}]}],
+	"buffers": [{BUFFER"byteLength": 42}],
+	"bufferViews": [
+		{"buffer": 0, "byteOffset": 0, "byteLength": 36},
+		{"buffer": 0, "byteOffset": 36, "byteLength": 6}
+	],
+	"accessors": [
+		{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+		{"bufferView": 1, "componentType": 5123, "count": 3, "type": "SCALAR"}
+	]
+}`
+
+// gltfEmbedded returns gltfTriangle with each replacement applied, as pairs
+// of old and new strings, and its buffer in a data uri.
+func gltfEmbedded(replacements ...string) string {
+	uri := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(gltfTriangleBuffer())
+	return edit(strings.Replace(gltfTriangle, "BUFFER", `"uri": "`+uri+`", `, 1), replacements...)
+}
+
+// glb packs gltfTriangle and its buffer into a binary container, with the
+// total length in the header adjusted by extra.
+func glb(version uint32, extra int) string {
+	doc := []byte(strings.Replace(gltfTriangle, "BUFFER", "", 1))
+	for len(doc)%4 != 0 {
+		doc = append(doc, ' ')
+	}
+	bin := gltfTriangleBuffer()
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+	length := 12 + 8 + len(doc) + 8 + len(bin)
+	return pack(binary.LittleEndian,
+		[]uint32{glbMagic, version, uint32(length + extra)},
+		[]uint32{uint32(len(doc)), glbChunkJSON}, doc,
+		[]uint32{uint32(len(bin)), glbChunkBIN}, bin)
+}
+
+func TestLoadGLTF(t *testing.T) {
+	testLoader(t, LoadGLTFReader, []loaderTest{
+		{"embedded", gltfEmbedded(), 1},
+		{"glb", glb(2, 0), 1},
+		{"no scenes", gltfEmbedded(`"scene": 0,`, "", `"scenes": [{"nodes": [0]}],`, ""), 1},
+		{"unindexed", gltfEmbedded(`, "indices": 1`, ""), 1},
+		{"ignored extension", gltfEmbedded(`"asset"`, `"extensionsRequired": ["KHR_materials_unlit"], "asset"`), 1},
+		{"empty", "", loadFails},
+		{"invalid json", gltfEmbedded()[1:], loadFails},
+		{"glb version", glb(1, 0), loadFails},
+		{"truncated glb", glb(2, 4), loadFails},
+		{"short glb header", glb(2, 0)[:16], loadFails},
+		{"required extension", gltfEmbedded(`"asset"`, `"extensionsRequired": ["KHR_draco_mesh_compression"], "asset"`), loadFails},
+		{"external buffer", strings.Replace(gltfTriangle, "BUFFER", `"uri": "missing.bin", `, 1), loadFails},
+		{"truncated buffer", gltfEmbedded(`"byteLength": 42`, `"byteLength": 100`), loadFails},
+		{"scene out of range", gltfEmbedded(`"scene": 0`, `"scene": 3`), loadFails},
+		{"node out of range", gltfEmbedded(`"nodes": [0]`, `"nodes": [5]`), loadFails},
+		{"node cycle", gltfEmbedded(`"mesh": 0,`, `"mesh": 0, "children": [0],`), loadFails},
+		{"mesh out of range", gltfEmbedded(`"mesh": 0`, `"mesh": 2`), loadFails},
+		{"bad matrix", gltfEmbedded(`"translation": [1, 0, 0]`, `"matrix": [1, 0, 0]`), loadFails},
+		{"lines", gltfEmbedded(`"indices": 1`, `"indices": 1, "mode": 1`), loadFails},
+		{"missing position", gltfEmbedded(`"POSITION"`, `"NORMAL"`), loadFails},
+		{"wrong type", gltfEmbedded(`"type": "VEC3"`, `"type": "VEC2"`), loadFails},
+		{"component type", gltfEmbedded(`"componentType": 5126`, `"componentType": 1234`), loadFails},
+		{"no buffer view", gltfEmbedded(`"bufferView": 0, `, ""), loadFails},
+		{"sparse", gltfEmbedded(`"type": "VEC3"`, `"type": "VEC3", "sparse": {}`), loadFails},
+		{"buffer view out of range", gltfEmbedded(`"bufferView": 1`, `"bufferView": 7`), loadFails},
+		{"buffer out of range", gltfEmbedded(`"buffer": 0, "byteOffset": 36`, `"buffer": 1, "byteOffset": 36`), loadFails},
+		{"view past buffer", gltfEmbedded(`"byteOffset": 36, "byteLength": 6`, `"byteOffset": 36, "byteLength": 60`), loadFails},
+		{"negative view offset", gltfEmbedded(`"byteOffset": 36`, `"byteOffset": -36`), loadFails},
+		{"small stride", gltfEmbedded(`"byteLength": 36}`, `"byteLength": 36, "byteStride": 4}`), loadFails},
+		{"count past view", gltfEmbedded(`"count": 3, "type": "VEC3"`, `"count": 4, "type": "VEC3"`), loadFails},
+		{"huge count", gltfEmbedded(`"count": 3, "type": "VEC3"`, `"count": 4611686018427387904, "type": "VEC3"`), loadFails},
+		{"negative count", gltfEmbedded(`"count": 3, "type": "SCALAR"`, `"count": -3, "type": "SCALAR"`), loadFails},
+		{"accessor offset", gltfEmbedded(`"componentType": 5123`, `"byteOffset": 2, "componentType": 5123`), loadFails},
+		{"index out of range", gltfEmbedded(`"count": 3, "type": "VEC3"`, `"count": 2, "type": "VEC3"`), loadFails},
+		{"no triangles", gltfEmbedded(`"nodes": [0]`, `"nodes": []`), loadFails},
+	})
+}
+
+func TestLoadGLTFTransforms(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		minX  float64
+	}{
+		{"translation", gltfEmbedded(), 1},
+		{"matrix", gltfEmbedded(`"translation": [1, 0, 0]`, `"matrix": [1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, -1, 0, 0, 1]`), -1},
+		{"mirrored", gltfEmbedded(`"translation": [1, 0, 0]`, `"scale": [-1, 1, 1]`), -2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := LoadGLTFReader(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x := data.Box.Min.X; x != test.minX {
+				t.Errorf("got min x %g, want %g", x, test.minX)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package meshview
+
+import (
+	"github.com/fogleman/fauxgl"
+)
+
+type MeshInfo struct {
+	Triangles        int
+	Vertices         int
+	Box              fauxgl.Box
+	Area             float64
+	Volume           float64
+	Components       int
+	BoundaryEdges    int
+	NonManifoldEdges int
+}
+
+// NewMeshInfo computes statistics for the mesh. Vertices are welded exactly
+// before counting vertices, components and edges.
+func NewMeshInfo(data *MeshData) *MeshInfo {
+	info := MeshInfo{}
+	info.Triangles = data.TriangleCount()
+	info.Box = data.Box
+
+	// area and signed volume
+	buffer := data.TriangleBuffer()
+	for i := 0; i+8 < len(buffer); i += 9 {
+		b := buffer[i:]
+		p1 := fauxgl.V(float64(b[0]), float64(b[1]), float64(b[2]))
+		p2 := fauxgl.V(float64(b[3]), float64(b[4]), float64(b[5]))
+		p3 := fauxgl.V(float64(b[6]), float64(b[7]), float64(b[8]))
+		info.Area += p2.Sub(p1).Cross(p3.Sub(p1)).Length() / 2
+		info.Volume += p1.Dot(p2.Cross(p3)) / 6
+	}
+
+	vertices, indices := weldVertices(buffer, 0)
+	info.Vertices = len(vertices) / 3
+
+	// connected components, joining the vertices of each triangle
+	parent := make([]uint32, info.Vertices)
+	for i := range parent {
+		parent[i] = uint32(i)
+	}
+	find := func(i uint32) uint32 {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	union := func(a, b uint32) {
+		a = find(a)
+		b = find(b)
+		if a != b {
+			parent[a] = b
+		}
+	}
+	for i := 0; i+2 < len(indices); i += 3 {
+		union(indices[i], indices[i+1])
+		union(indices[i], indices[i+2])
+	}
+	for i := range parent {
+		if find(uint32(i)) == uint32(i) {
+			info.Components++
+		}
+	}
+
+	// count how many triangles share each undirected edge
+	edges := make(map[uint64]int)
+	for i := 0; i+2 < len(indices); i += 3 {
+		for j := 0; j < 3; j++ {
+			a := indices[i+j]
+			b := indices[i+(j+1)%3]
+			if a == b {
+				continue
+			}
+			if a > b {
+				a, b = b, a
+			}
+			edges[uint64(a)<<32|uint64(b)]++
+		}
+	}
+	for _, count := range edges {
+		if count == 1 {
+			info.BoundaryEdges++
+		} else if count > 2 {
+			info.NonManifoldEdges++
+		}
+	}
+
+	return &info
+}
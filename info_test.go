@@ -0,0 +1,26 @@
+package meshview
+
+import "testing"
+
+func TestNewMeshInfo(t *testing.T) {
+	// two triangles sharing only a vertex
+	soup := []float32{
+		0, 0, 0, 1, 0, 0, 0, 1, 0,
+		0, 0, 0, -1, 0, 0, 0, 0, 1,
+	}
+	tests := []struct {
+		name string
+		data *MeshData
+		want MeshInfo
+	}{
+		{"cube", cubeMesh(), MeshInfo{12, 8, cubeMesh().Box, 24, 8, 1, 0, 0}},
+		{"soup", &MeshData{Buffer: soup, Box: boxForData(soup)}, MeshInfo{2, 5, boxForData(soup), 1, 0, 1, 6, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NewMeshInfo(test.data); *got != test.want {
+				t.Errorf("got %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
@@ -1,50 +1,57 @@
 package meshview
 
 import (
+	"math"
+
 	"github.com/fogleman/fauxgl"
-	"github.com/go-gl/gl/v2.1/gl"
 )
 
+// MeshData holds vertex positions as flat x, y, z triples. Without Indices,
+// every three vertices form a triangle. With Indices, Buffer holds unique
+// vertices and every three indices form a triangle. Normals, if present,
+// holds one normal per vertex in Buffer. Warnings lists malformed records
+// that were skipped by a lenient load.
 type MeshData struct {
-	Buffer []float32
-	Box    fauxgl.Box
-}
-
-type Mesh struct {
-	Transform    fauxgl.Matrix
-	VertexBuffer uint32
-	VertexCount  int32
+	Buffer   []float32
+	Indices  []uint32
+	Normals  []float32
+	Box      fauxgl.Box
+	Warnings []error
 }
 
-func NewMesh(data *MeshData) *Mesh {
-	// compute transform to scale and center mesh
-	scale := fauxgl.V(2, 2, 2).Div(data.Box.Size()).MinComponent()
-	transform := fauxgl.Identity()
-	transform = transform.Translate(data.Box.Center().Negate())
-	transform = transform.Scale(fauxgl.V(scale, scale, scale))
-
-	// generate vbo
-	var vbo uint32
-	gl.GenBuffers(1, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(data.Buffer)*4, gl.Ptr(data.Buffer), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	// compute number of vertices
-	count := int32(len(data.Buffer) / 3)
-
-	return &Mesh{transform, vbo, count}
+func (data *MeshData) TriangleCount() int {
+	if data.Indices != nil {
+		return len(data.Indices) / 3
+	}
+	return len(data.Buffer) / 9
 }
 
-func (mesh *Mesh) Draw(positionAttrib uint32) {
-	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.VertexBuffer)
-	gl.EnableVertexAttribArray(positionAttrib)
-	gl.VertexAttribPointer(positionAttrib, 3, gl.FLOAT, false, 12, gl.PtrOffset(0))
-	gl.DrawArrays(gl.TRIANGLES, 0, mesh.VertexCount)
-	gl.DisableVertexAttribArray(positionAttrib)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+// TriangleBuffer returns three vertex positions per triangle, expanding the
+// index buffer if there is one.
+func (data *MeshData) TriangleBuffer() []float32 {
+	if data.Indices == nil {
+		return data.Buffer
+	}
+	buffer := make([]float32, len(data.Indices)*3)
+	parallel(len(data.Indices), func(i0, i1 int) {
+		for i := i0; i < i1; i++ {
+			j := data.Indices[i] * 3
+			copy(buffer[i*3:i*3+3], data.Buffer[j:j+3])
+		}
+	})
+	return buffer
 }
 
-func (mesh *Mesh) Destroy() {
-	gl.DeleteBuffers(1, &mesh.VertexBuffer)
+// MeshTransform centers box at the origin and scales it to fit the cube from
+// -1 to 1, which is how the viewer and Render frame a mesh.
+func MeshTransform(box fauxgl.Box) fauxgl.Matrix {
+	scale := fauxgl.V(2, 2, 2).Div(box.Size()).MinComponent()
+	if math.IsInf(scale, 0) {
+		// all of the triangles are at one point
+		scale = 1
+	}
+	transform := fauxgl.Identity()
+	transform = transform.Translate(box.Center().Negate())
+	transform = transform.Scale(fauxgl.V(scale, scale, scale))
+	return transform
 }
@@ -0,0 +1,121 @@
+package meshview
+
+import (
+	"math"
+
+	"github.com/fogleman/fauxgl"
+)
+
+// DefaultCreaseAngle is the angle in degrees between adjacent faces above
+// which the viewer keeps the edge between them sharp when computing vertex
+// normals.
+const DefaultCreaseAngle = 30.0
+
+// ComputeNormals sets a normal for every vertex, averaging the normals of the
+// surrounding faces weighted by their angle at the vertex. Faces that differ
+// from each other by more than creaseAngle degrees are not averaged, so
+// vertices along sharp edges are split into one vertex per side. Triangle
+// soup is not welded first, so its faces keep flat normals unless it has
+// been welded beforehand.
+func (data *MeshData) ComputeNormals(creaseAngle float64) {
+	positions := data.Buffer
+	indices := data.Indices
+	if indices == nil {
+		indices = make([]uint32, len(positions)/3)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	// face normals and the angle of each face corner
+	faceNormals := make([]fauxgl.Vector, len(indices)/3)
+	cornerAngles := make([]float64, len(indices))
+	parallel(len(faceNormals), func(i0, i1 int) {
+		for i := i0; i < i1; i++ {
+			var p [3]fauxgl.Vector
+			for j := range p {
+				k := indices[i*3+j] * 3
+				p[j] = fauxgl.V(float64(positions[k]), float64(positions[k+1]), float64(positions[k+2]))
+			}
+			n := p[1].Sub(p[0]).Cross(p[2].Sub(p[0]))
+			if n.Length() > 0 {
+				faceNormals[i] = n.Normalize()
+			}
+			for j := range p {
+				e1 := p[(j+1)%3].Sub(p[j])
+				e2 := p[(j+2)%3].Sub(p[j])
+				d := e1.Length() * e2.Length()
+				if d > 0 {
+					cornerAngles[i*3+j] = math.Acos(math.Max(-1, math.Min(1, e1.Dot(e2)/d)))
+				}
+			}
+		}
+	})
+
+	// list the corners around each vertex
+	vertexCount := len(positions) / 3
+	offsets := make([]int, vertexCount+1)
+	for _, v := range indices {
+		offsets[v+1]++
+	}
+	for i := 1; i < len(offsets); i++ {
+		offsets[i] += offsets[i-1]
+	}
+	corners := make([]int, len(indices))
+	next := make([]int, vertexCount)
+	copy(next, offsets)
+	for i, v := range indices {
+		corners[next[v]] = i
+		next[v]++
+	}
+
+	// average normals for each corner, sharing output vertices between
+	// corners that end up with the same normal
+	threshold := math.Cos(creaseAngle * math.Pi / 180)
+	newPositions := make([]float32, 0, len(positions))
+	newNormals := make([]float32, 0, len(positions))
+	newIndices := make([]uint32, len(indices))
+	type output struct {
+		normal fauxgl.Vector
+		index  uint32
+	}
+	var outputs []output
+	for v := 0; v < vertexCount; v++ {
+		cs := corners[offsets[v]:offsets[v+1]]
+		outputs = outputs[:0]
+		for _, c := range cs {
+			fn := faceNormals[c/3]
+			var n fauxgl.Vector
+			for _, d := range cs {
+				gn := faceNormals[d/3]
+				if fn.Dot(gn) >= threshold {
+					n = n.Add(gn.MulScalar(cornerAngles[d]))
+				}
+			}
+			if n.Length() > 0 {
+				n = n.Normalize()
+			} else {
+				n = fn
+			}
+			found := false
+			for _, o := range outputs {
+				if o.normal.Dot(n) > 1-1e-6 {
+					newIndices[c] = o.index
+					found = true
+					break
+				}
+			}
+			if !found {
+				index := uint32(len(newPositions) / 3)
+				newPositions = append(newPositions, positions[v*3:v*3+3]...)
+				newNormals = append(newNormals, float32(n.X), float32(n.Y), float32(n.Z))
+				outputs = append(outputs, output{n, index})
+				newIndices[c] = index
+			}
+		}
+	}
+
+	data.Buffer = newPositions
+	data.Indices = newIndices
+	data.Normals = newNormals
+}
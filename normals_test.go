@@ -0,0 +1,51 @@
+package meshview
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeNormals(t *testing.T) {
+	// the edges of a cube are 90 degrees, so each corner is split into one
+	// vertex per face unless the crease angle is larger
+	tests := []struct {
+		name     string
+		crease   float64
+		vertices int
+		axes     int
+	}{
+		{"sharp", DefaultCreaseAngle, 24, 1},
+		{"smooth", 180, 8, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := cubeMesh()
+			data.ComputeNormals(test.crease)
+			if n := len(data.Buffer) / 3; n != test.vertices {
+				t.Errorf("got %d vertices, want %d", n, test.vertices)
+			}
+			if len(data.Normals) != len(data.Buffer) || len(data.Indices) != 36 {
+				t.Fatalf("got %d normals and %d indices", len(data.Normals)/3, len(data.Indices))
+			}
+			// each normal points out of the cube along as many axes as the
+			// faces it averages
+			want := 1 / math.Sqrt(float64(test.axes))
+			for i := 0; i < len(data.Normals); i += 3 {
+				axes := 0
+				for j := i; j < i+3; j++ {
+					n := float64(data.Normals[j])
+					if math.Abs(n) < 1e-6 {
+						continue
+					}
+					if math.Abs(n*float64(data.Buffer[j])-want) > 1e-6 {
+						t.Fatalf("vertex %d: got normal %v", i/3, data.Normals[i:i+3])
+					}
+					axes++
+				}
+				if axes != test.axes {
+					t.Fatalf("vertex %d: got normal %v", i/3, data.Normals[i:i+3])
+				}
+			}
+		})
+	}
+}
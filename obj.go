@@ -2,35 +2,102 @@ package meshview
 
 import (
 	"bufio"
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 )
 
-func parseIndex(value string, count int) int {
-	parsed, _ := strconv.ParseInt(value, 0, 0)
+// parseIndex resolves a one-based, possibly negative, obj index against
+// the count of elements defined so far, including the unused zero slot.
+func parseIndex(value string, count int) (int, error) {
+	parsed, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("index %q is not a number", value)
+	}
 	n := int(parsed)
+	if n == 0 {
+		return 0, fmt.Errorf("index 0 is invalid, indices start at 1")
+	}
 	if n < 0 {
 		n += count
 	}
-	return n
+	if n < 1 || n >= count {
+		return 0, fmt.Errorf("index %s out of range, %d defined", value, count-1)
+	}
+	return n, nil
 }
 
 func LoadOBJ(path string) (*MeshData, error) {
+	return loadOBJFile(context.Background(), path, false)
+}
+
+func loadOBJFile(ctx context.Context, path string, lenient bool) (*MeshData, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
+	return loadOBJ(contextFile{ctx, file}, path, lenient)
+}
+
+func LoadOBJReader(r io.Reader) (*MeshData, error) {
+	return loadOBJ(r, "", false)
+}
 
+func loadOBJ(file io.Reader, name string, lenient bool) (*MeshData, error) {
+	report := parseReport{Path: name, Lenient: lenient}
 	count := 1
 	lookup := make([]float32, 3, 1024)
-	var data []float32
-	var indexes []int
+	normalCount := 1
+	normalLookup := make([]float32, 3)
+
+	// malformed vertices still take up an index in lenient mode, so that
+	// later faces refer to the right vertices, but faces using them are
+	// skipped
+	badVertices := make(map[int]bool)
+	badNormals := make(map[int]bool)
+
+	// output vertices are unique pairs of position and normal index. remap
+	// holds the first output vertex for each position, and pairs holds any
+	// others for positions that are used with more than one normal.
+	remap := make([]uint32, 1)
+	remapNormal := make([]int, 1)
+	pairs := make(map[[2]int]uint32)
+	missingNormals := false
+	var vertices, normals []float32
+	vertex := func(v, n int) uint32 {
+		if remap[v] != 0 && remapNormal[v] == n {
+			return remap[v] - 1
+		}
+		key := [2]int{v, n}
+		if remap[v] != 0 {
+			if index, ok := pairs[key]; ok {
+				return index
+			}
+		}
+		index := uint32(len(vertices) / 3)
+		vertices = append(vertices, lookup[v*3:v*3+3]...)
+		normals = append(normals, normalLookup[n*3:n*3+3]...)
+		if remap[v] == 0 {
+			remap[v] = index + 1
+			remapNormal[v] = n
+		} else {
+			pairs[key] = index
+		}
+		return index
+	}
+
+	var indices []uint32
+	var corners [][2]int
+	var indexes []uint32
+	line := 0
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Fields(line)
+		line++
+		fields := strings.Fields(scanner.Text())
 		if len(fields) == 0 {
 			continue
 		}
@@ -38,33 +105,115 @@ func LoadOBJ(path string) (*MeshData, error) {
 		args := fields[1:]
 		switch keyword {
 		case "v":
-			x, _ := strconv.ParseFloat(args[0], 32)
-			y, _ := strconv.ParseFloat(args[1], 32)
-			z, _ := strconv.ParseFloat(args[2], 32)
-			v := []float32{float32(x), float32(y), float32(z)}
-			lookup = append(lookup, v...)
+			x, y, z, err := parseVector(args)
+			if err != nil {
+				if err := report.add(line, "vertex: %v", err); err != nil {
+					return nil, err
+				}
+				badVertices[count] = true
+			}
+			lookup = append(lookup, x, y, z)
+			remap = append(remap, 0)
+			remapNormal = append(remapNormal, 0)
 			count++
+		case "vn":
+			x, y, z, err := parseVector(args)
+			if err != nil {
+				if err := report.add(line, "normal: %v", err); err != nil {
+					return nil, err
+				}
+				badNormals[normalCount] = true
+			}
+			normalLookup = append(normalLookup, x, y, z)
+			normalCount++
 		case "f":
-			indexes = indexes[:0]
+			if len(args) < 3 {
+				if err := report.add(line, "face: expected at least 3 vertices, got %d", len(args)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			corners = corners[:0]
+			var faceErr error
 			for _, arg := range args {
-				i := strings.Index(arg, "/")
-				if i >= 0 {
-					arg = arg[:i]
+				parts := strings.Split(arg, "/")
+				index, err := parseIndex(parts[0], count)
+				if err != nil {
+					faceErr = fmt.Errorf("vertex %v", err)
+					break
+				}
+				if badVertices[index] {
+					faceErr = fmt.Errorf("vertex %d is malformed", index)
+					break
+				}
+				normalIndex := 0
+				if len(parts) >= 3 && parts[2] != "" {
+					normalIndex, err = parseIndex(parts[2], normalCount)
+					if err != nil {
+						faceErr = fmt.Errorf("normal %v", err)
+						break
+					}
+					if badNormals[normalIndex] {
+						faceErr = fmt.Errorf("normal %d is malformed", normalIndex)
+						break
+					}
+				}
+				corners = append(corners, [2]int{index, normalIndex})
+			}
+			if faceErr != nil {
+				if err := report.add(line, "face: %v", faceErr); err != nil {
+					return nil, err
 				}
-				index := parseIndex(arg, count)
-				indexes = append(indexes, index)
+				continue
+			}
+			indexes = indexes[:0]
+			for _, c := range corners {
+				if c[1] == 0 {
+					missingNormals = true
+				}
+				indexes = append(indexes, vertex(c[0], c[1]))
 			}
 			for i := 1; i < len(indexes)-1; i++ {
-				i1 := indexes[0] * 3
-				i2 := indexes[i] * 3
-				i3 := indexes[i+1] * 3
-				data = append(data, lookup[i1:i1+3]...)
-				data = append(data, lookup[i2:i2+3]...)
-				data = append(data, lookup[i3:i3+3]...)
+				indices = append(indices, indexes[0], indexes[i], indexes[i+1])
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-	box := boxForData(data)
-	return &MeshData{data, box}, scanner.Err()
+	box := boxForData(vertices)
+	data := &MeshData{Buffer: vertices, Indices: indices, Normals: normals, Box: box}
+	data.Warnings = report.Warnings
+
+	// normals are only used if every face vertex has one, in which case
+	// positions split by normal are merged again
+	if missingNormals {
+		data.Normals = nil
+		if len(pairs) > 0 {
+			data.Weld(0)
+		}
+	}
+	return data, nil
+}
+
+func SaveOBJ(path string, data *MeshData) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	vertices, indices := weldVertices(data.TriangleBuffer(), 0)
+	for i := 0; i+2 < len(vertices); i += 3 {
+		fmt.Fprintf(writer, "v %s %s %s\n",
+			formatFloat(vertices[i]), formatFloat(vertices[i+1]), formatFloat(vertices[i+2]))
+	}
+	for i := 0; i+2 < len(indices); i += 3 {
+		fmt.Fprintf(writer, "f %d %d %d\n", indices[i]+1, indices[i+1]+1, indices[i+2]+1)
+	}
+	err = writer.Flush()
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
@@ -0,0 +1,64 @@
+package meshview
+
+import (
+	"strings"
+	"testing"
+)
+
+const objQuad = `# a unit square
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vn 0 0 1
+f 1//1 2//1 3//1 4//1
+`
+
+func TestLoadOBJ(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		triangles int
+		vertices  int
+		normals   bool
+	}{
+		{"quad", objQuad, 2, 4, true},
+		{"no normals", edit(objQuad, "f 1//1 2//1 3//1 4//1", "f 1 2 3 4"), 2, 4, false},
+		{"some normals", edit(objQuad, "f 1//1 2//1 3//1 4//1", "f 1//1 2//1 3//1\nf 1 3 4"), 2, 4, false},
+		{"negative indices", edit(objQuad, "f 1//1 2//1 3//1 4//1", "f -4/1/-1 -3/2/-1 -2/3/-1 -1/4/-1"), 2, 4, true},
+		{"texture coordinates", edit(objQuad, "f 1//1 2//1 3//1 4//1", "vt 0 0\nf 1/1 2/1 3/1"), 1, 3, false},
+		{"other keywords", "mtllib x.mtl\no quad\ng part\ns 1\nusemtl red\n" + objQuad + "l 1 2\n", 2, 4, true},
+		{"crlf", edit(objQuad, "\n", "\r\n"), 2, 4, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := LoadOBJReader(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := data.TriangleCount(); n != test.triangles {
+				t.Errorf("got %d triangles, want %d", n, test.triangles)
+			}
+			if n := len(data.Buffer) / 3; n != test.vertices {
+				t.Errorf("got %d vertices, want %d", n, test.vertices)
+			}
+			if normals := data.Normals != nil; normals != test.normals {
+				t.Errorf("got normals %v, want %v", normals, test.normals)
+			}
+		})
+	}
+}
+
+func TestLoadOBJMalformed(t *testing.T) {
+	testLineErrors(t, loadOBJ, []lineTest{
+		{"bad vertex", edit(objQuad, "v 1 1 0", "v 1 one 0"), 4, 0},
+		{"short vertex", edit(objQuad, "v 0 1 0", "v 0 1"), 5, 0},
+		{"bad normal", edit(objQuad, "vn 0 0 1", "vn 0 0 z"), 6, 0},
+		{"index zero", objQuad + "f 0 1 2\n", 8, 2},
+		{"index out of range", objQuad + "f 1 2 5\n", 8, 2},
+		{"negative index out of range", objQuad + "f -5 1 2\n", 8, 2},
+		{"index not a number", objQuad + "f 1 2 x\n", 8, 2},
+		{"normal out of range", objQuad + "f 1//2 2//1 3//1\n", 8, 2},
+		{"two vertices", objQuad + "f 1 2\n", 8, 2},
+	})
+}
@@ -0,0 +1,498 @@
+package meshview
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type plyFormat int
+
+const (
+	plyASCII plyFormat = iota
+	plyBinaryLittleEndian
+	plyBinaryBigEndian
+)
+
+type plyType int
+
+const (
+	plyInvalid plyType = iota
+	plyInt8
+	plyUint8
+	plyInt16
+	plyUint16
+	plyInt32
+	plyUint32
+	plyFloat32
+	plyFloat64
+)
+
+var plyTypes = map[string]plyType{
+	"char":    plyInt8,
+	"uchar":   plyUint8,
+	"short":   plyInt16,
+	"ushort":  plyUint16,
+	"int":     plyInt32,
+	"uint":    plyUint32,
+	"float":   plyFloat32,
+	"double":  plyFloat64,
+	"int8":    plyInt8,
+	"uint8":   plyUint8,
+	"int16":   plyInt16,
+	"uint16":  plyUint16,
+	"int32":   plyInt32,
+	"uint32":  plyUint32,
+	"float32": plyFloat32,
+	"float64": plyFloat64,
+}
+
+func (t plyType) size() int {
+	switch t {
+	case plyInt8, plyUint8:
+		return 1
+	case plyInt16, plyUint16:
+		return 2
+	case plyInt32, plyUint32, plyFloat32:
+		return 4
+	case plyFloat64:
+		return 8
+	}
+	return 0
+}
+
+func (t plyType) decode(b []byte, order binary.ByteOrder) float64 {
+	switch t {
+	case plyInt8:
+		return float64(int8(b[0]))
+	case plyUint8:
+		return float64(b[0])
+	case plyInt16:
+		return float64(int16(order.Uint16(b)))
+	case plyUint16:
+		return float64(order.Uint16(b))
+	case plyInt32:
+		return float64(int32(order.Uint32(b)))
+	case plyUint32:
+		return float64(order.Uint32(b))
+	case plyFloat32:
+		return float64(math.Float32frombits(order.Uint32(b)))
+	case plyFloat64:
+		return math.Float64frombits(order.Uint64(b))
+	}
+	return 0
+}
+
+type plyProperty struct {
+	Name      string
+	Type      plyType
+	CountType plyType
+	List      bool
+}
+
+type plyElement struct {
+	Name       string
+	Count      int
+	Properties []plyProperty
+}
+
+// stride returns the size in bytes of one binary record of the element, or
+// -1 if the element contains list properties and has no fixed size.
+func (e *plyElement) stride() int {
+	stride := 0
+	for _, p := range e.Properties {
+		if p.List {
+			return -1
+		}
+		stride += p.Type.size()
+	}
+	return stride
+}
+
+func (e *plyElement) offset(name string) (int, int) {
+	offset := 0
+	for i, p := range e.Properties {
+		if p.Name == name {
+			return i, offset
+		}
+		offset += p.Type.size()
+	}
+	return -1, -1
+}
+
+func (e *plyElement) faceProperty() int {
+	for i, p := range e.Properties {
+		if p.List && (p.Name == "vertex_indices" || p.Name == "vertex_index") {
+			return i
+		}
+	}
+	for i, p := range e.Properties {
+		if p.List {
+			return i
+		}
+	}
+	return -1
+}
+
+// plyChunkSize is the number of fixed size binary records read at a time.
+const plyChunkSize = 1 << 16
+
+type plyHeader struct {
+	Format   plyFormat
+	Elements []*plyElement
+}
+
+func readPLYHeader(reader *bufio.Reader) (*plyHeader, error) {
+	header := plyHeader{}
+	var element *plyElement
+	for i := 0; ; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("ply: truncated header: %v", err)
+		}
+		fields := strings.Fields(line)
+		if i == 0 {
+			if len(fields) != 1 || fields[0] != "ply" {
+				return nil, fmt.Errorf("ply: missing magic number")
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("ply: invalid format line: %s", line)
+			}
+			switch fields[1] {
+			case "ascii":
+				header.Format = plyASCII
+			case "binary_little_endian":
+				header.Format = plyBinaryLittleEndian
+			case "binary_big_endian":
+				header.Format = plyBinaryBigEndian
+			default:
+				return nil, fmt.Errorf("ply: unsupported format: %s", fields[1])
+			}
+		case "element":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("ply: invalid element line: %s", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("ply: invalid element count: %s", fields[2])
+			}
+			element = &plyElement{Name: fields[1], Count: count}
+			header.Elements = append(header.Elements, element)
+		case "property":
+			if element == nil {
+				return nil, fmt.Errorf("ply: property before element: %s", line)
+			}
+			var p plyProperty
+			if len(fields) == 5 && fields[1] == "list" {
+				p = plyProperty{fields[4], plyTypes[fields[3]], plyTypes[fields[2]], true}
+				if p.CountType == plyInvalid || p.CountType == plyFloat32 || p.CountType == plyFloat64 {
+					return nil, fmt.Errorf("ply: invalid list count type: %s", fields[2])
+				}
+			} else if len(fields) == 3 {
+				p = plyProperty{fields[2], plyTypes[fields[1]], plyInvalid, false}
+			} else {
+				return nil, fmt.Errorf("ply: invalid property line: %s", line)
+			}
+			if p.Type == plyInvalid {
+				return nil, fmt.Errorf("ply: invalid property type: %s", line)
+			}
+			element.Properties = append(element.Properties, p)
+		case "end_header":
+			return &header, nil
+		}
+	}
+}
+
+func LoadPLY(path string) (*MeshData, error) {
+	return loadPLYFile(context.Background(), path)
+}
+
+func loadPLYFile(ctx context.Context, path string) (*MeshData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadPLYReader(contextFile{ctx, file})
+}
+
+func LoadPLYReader(r io.Reader) (*MeshData, error) {
+	reader := bufio.NewReader(r)
+	header, err := readPLYHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var lookup []float32
+	var triangles []int
+	switch header.Format {
+	case plyASCII:
+		lookup, triangles, err = loadPLYA(reader, header)
+	case plyBinaryLittleEndian:
+		lookup, triangles, err = loadPLYB(reader, header, binary.LittleEndian)
+	case plyBinaryBigEndian:
+		lookup, triangles, err = loadPLYB(reader, header, binary.BigEndian)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	count := len(lookup) / 3
+	indices := make([]uint32, len(triangles))
+	for i, index := range triangles {
+		if index < 0 || index >= count {
+			return nil, fmt.Errorf("ply: vertex index %d out of range", index)
+		}
+		indices[i] = uint32(index)
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("ply: no faces")
+	}
+
+	vertices := compactVertices(lookup, indices)
+	box := boxForData(vertices)
+	return &MeshData{Buffer: vertices, Indices: indices, Box: box}, nil
+}
+
+func appendFan(triangles []int, indexes []int) []int {
+	for i := 1; i < len(indexes)-1; i++ {
+		triangles = append(triangles, indexes[0], indexes[i], indexes[i+1])
+	}
+	return triangles
+}
+
+func loadPLYA(reader *bufio.Reader, header *plyHeader) ([]float32, []int, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(bufio.ScanWords)
+	next := func() (float64, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.ErrUnexpectedEOF
+		}
+		return strconv.ParseFloat(scanner.Text(), 64)
+	}
+
+	var lookup []float32
+	var triangles []int
+	var indexes []int
+	for _, e := range header.Elements {
+		isVertex := e.Name == "vertex"
+		isFace := e.Name == "face"
+		xi, _ := e.offset("x")
+		yi, _ := e.offset("y")
+		zi, _ := e.offset("z")
+		fi := e.faceProperty()
+		if isVertex && (xi < 0 || yi < 0 || zi < 0) {
+			return nil, nil, fmt.Errorf("ply: vertex element missing x, y or z")
+		}
+		if len(e.Properties) == 0 {
+			continue
+		}
+		// the count comes from the header, so vertices are added as they
+		// are read rather than allocated up front
+		for i := 0; i < e.Count; i++ {
+			if isVertex {
+				lookup = append(lookup, 0, 0, 0)
+			}
+			for pi, p := range e.Properties {
+				n := 1
+				if p.List {
+					value, err := next()
+					if err != nil {
+						return nil, nil, fmt.Errorf("ply: %s %d: %v", e.Name, i, err)
+					}
+					n = int(value)
+				}
+				face := isFace && pi == fi
+				indexes = indexes[:0]
+				for j := 0; j < n; j++ {
+					value, err := next()
+					if err != nil {
+						return nil, nil, fmt.Errorf("ply: %s %d: %v", e.Name, i, err)
+					}
+					if face {
+						indexes = append(indexes, int(value))
+					} else if isVertex {
+						switch pi {
+						case xi:
+							lookup[i*3+0] = float32(value)
+						case yi:
+							lookup[i*3+1] = float32(value)
+						case zi:
+							lookup[i*3+2] = float32(value)
+						}
+					}
+				}
+				if face {
+					triangles = appendFan(triangles, indexes)
+				}
+			}
+		}
+	}
+	return lookup, triangles, nil
+}
+
+func loadPLYB(reader *bufio.Reader, header *plyHeader, order binary.ByteOrder) ([]float32, []int, error) {
+	var lookup []float32
+	var triangles []int
+	var indexes []int
+	scratch := make([]byte, 8)
+	for _, e := range header.Elements {
+		isVertex := e.Name == "vertex"
+		isFace := e.Name == "face"
+		xi, xo := e.offset("x")
+		yi, yo := e.offset("y")
+		zi, zo := e.offset("z")
+		fi := e.faceProperty()
+		if isVertex && (xi < 0 || yi < 0 || zi < 0) {
+			return nil, nil, fmt.Errorf("ply: vertex element missing x, y or z")
+		}
+		if len(e.Properties) == 0 {
+			continue
+		}
+
+		// fixed size records are read in chunks, so that a corrupt count
+		// in the header fails at the end of the input instead of
+		// allocating for it up front, and each chunk is decoded in parallel
+		stride := e.stride()
+		if stride >= 0 {
+			chunk := plyChunkSize
+			if e.Count < chunk {
+				chunk = e.Count
+			}
+			buf := make([]byte, chunk*stride)
+			for start := 0; start < e.Count; start += chunk {
+				n := e.Count - start
+				if n > chunk {
+					n = chunk
+				}
+				b := buf[:n*stride]
+				if _, err := io.ReadFull(reader, b); err != nil {
+					return nil, nil, fmt.Errorf("ply: %s: %v", e.Name, err)
+				}
+				if !isVertex {
+					continue
+				}
+				xt := e.Properties[xi].Type
+				yt := e.Properties[yi].Type
+				zt := e.Properties[zi].Type
+				offset := len(lookup)
+				lookup = append(lookup, make([]float32, n*3)...)
+				parallel(n, func(i0, i1 int) {
+					for i := i0; i < i1; i++ {
+						r := b[i*stride:]
+						v := lookup[offset+i*3:]
+						v[0] = float32(xt.decode(r[xo:], order))
+						v[1] = float32(yt.decode(r[yo:], order))
+						v[2] = float32(zt.decode(r[zo:], order))
+					}
+				})
+			}
+			continue
+		}
+
+		// records containing lists are decoded sequentially
+		for i := 0; i < e.Count; i++ {
+			if isVertex {
+				lookup = append(lookup, 0, 0, 0)
+			}
+			for pi, p := range e.Properties {
+				n := 1
+				if p.List {
+					b := scratch[:p.CountType.size()]
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return nil, nil, fmt.Errorf("ply: %s %d: %v", e.Name, i, err)
+					}
+					n = int(p.CountType.decode(b, order))
+				}
+				face := isFace && pi == fi
+				indexes = indexes[:0]
+				for j := 0; j < n; j++ {
+					b := scratch[:p.Type.size()]
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return nil, nil, fmt.Errorf("ply: %s %d: %v", e.Name, i, err)
+					}
+					value := p.Type.decode(b, order)
+					if face {
+						indexes = append(indexes, int(value))
+					} else if isVertex {
+						switch pi {
+						case xi:
+							lookup[i*3+0] = float32(value)
+						case yi:
+							lookup[i*3+1] = float32(value)
+						case zi:
+							lookup[i*3+2] = float32(value)
+						}
+					}
+				}
+				if face {
+					triangles = appendFan(triangles, indexes)
+				}
+			}
+		}
+	}
+	return lookup, triangles, nil
+}
+
+func SavePLY(path string, data *MeshData, ascii bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	vertices, indices := weldVertices(data.TriangleBuffer(), 0)
+	format := "binary_little_endian"
+	if ascii {
+		format = "ascii"
+	}
+	fmt.Fprintf(writer, "ply\nformat %s 1.0\n", format)
+	fmt.Fprintf(writer, "element vertex %d\n", len(vertices)/3)
+	fmt.Fprintf(writer, "property float x\nproperty float y\nproperty float z\n")
+	fmt.Fprintf(writer, "element face %d\n", len(indices)/3)
+	fmt.Fprintf(writer, "property list uchar int vertex_indices\nend_header\n")
+	if ascii {
+		for i := 0; i+2 < len(vertices); i += 3 {
+			fmt.Fprintf(writer, "%s %s %s\n",
+				formatFloat(vertices[i]), formatFloat(vertices[i+1]), formatFloat(vertices[i+2]))
+		}
+		for i := 0; i+2 < len(indices); i += 3 {
+			fmt.Fprintf(writer, "3 %d %d %d\n", indices[i], indices[i+1], indices[i+2])
+		}
+	} else {
+		buf := make([]byte, 13)
+		for i := 0; i+2 < len(vertices); i += 3 {
+			binary.LittleEndian.PutUint32(buf[0:], math.Float32bits(vertices[i]))
+			binary.LittleEndian.PutUint32(buf[4:], math.Float32bits(vertices[i+1]))
+			binary.LittleEndian.PutUint32(buf[8:], math.Float32bits(vertices[i+2]))
+			writer.Write(buf[:12])
+		}
+		buf[0] = 3
+		for i := 0; i+2 < len(indices); i += 3 {
+			binary.LittleEndian.PutUint32(buf[1:], indices[i])
+			binary.LittleEndian.PutUint32(buf[5:], indices[i+1])
+			binary.LittleEndian.PutUint32(buf[9:], indices[i+2])
+			writer.Write(buf)
+		}
+	}
+	err = writer.Flush()
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
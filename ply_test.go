@@ -0,0 +1,102 @@
+package meshview
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+const plyASCIIQuad = `ply
+format ascii 1.0
+comment a unit square split into two triangles by the fan
+element vertex 4
+property float x
+property float y
+property float z
+property uchar red
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0 255
+1 0 0 255
+1 1 0 255
+0 1 0 255
+4 0 1 2 3
+`
+
+const plyTriangleHeader = `element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list uchar uint vertex_indices
+`
+
+// plyBinary returns a binary ply with the given header body, followed by the
+// values written in order.
+func plyBinary(order binary.ByteOrder, body string, values ...interface{}) string {
+	format := "binary_little_endian"
+	if order == binary.BigEndian {
+		format = "binary_big_endian"
+	}
+	return "ply\nformat " + format + " 1.0\n" + body + "end_header\n" + pack(order, values...)
+}
+
+// plyTriangle returns a binary ply of a right triangle, with its header edited
+// by pairs of old and new strings.
+func plyTriangle(order binary.ByteOrder, pairs ...string) string {
+	return plyBinary(order, edit(plyTriangleHeader, pairs...),
+		[]float32{0, 0, 0, 2, 0, 0, 0, 3, 0}, uint8(3), []uint32{0, 1, 2})
+}
+
+func TestLoadPLY(t *testing.T) {
+	testLoader(t, LoadPLYReader, []loaderTest{
+		{"ascii", plyASCIIQuad, 2},
+		{"crlf header", edit(plyASCIIQuad, "\n", "\r\n"), 2},
+		{"little endian", plyTriangle(binary.LittleEndian), 1},
+		{"big endian", plyTriangle(binary.BigEndian), 1},
+		{"empty", "", loadFails},
+		{"no magic", "format ascii 1.0\nend_header\n", loadFails},
+		{"truncated header", "ply\nformat ascii 1.0\nelement vertex 3\n", loadFails},
+		{"unsupported format", "ply\nformat binary_middle_endian 1.0\nend_header\n", loadFails},
+		{"bad element count", "ply\nformat ascii 1.0\nelement vertex -1\nend_header\n", loadFails},
+		{"property before element", "ply\nformat ascii 1.0\nproperty float x\nend_header\n", loadFails},
+		{"bad property type", "ply\nformat ascii 1.0\nelement vertex 1\nproperty quad x\nend_header\n", loadFails},
+		{"float list count", "ply\nformat ascii 1.0\nelement face 1\nproperty list float int vertex_indices\nend_header\n", loadFails},
+		{"missing z", edit(plyASCIIQuad, "property float z\n", ""), loadFails},
+		{"bad number", edit(plyASCIIQuad, "1 1 0 255", "1 one 0 255"), loadFails},
+		{"truncated ascii", plyASCIIQuad[:len(plyASCIIQuad)-4], loadFails},
+		{"index out of range", edit(plyASCIIQuad, "4 0 1 2 3", "4 0 1 2 4"), loadFails},
+		{"negative index", edit(plyASCIIQuad, "4 0 1 2 3", "4 0 1 2 -1"), loadFails},
+		{"no faces", edit(plyASCIIQuad, "4 0 1 2 3", "2 0 1"), loadFails},
+		{"truncated vertices", plyBinary(binary.LittleEndian, plyTriangleHeader, []float32{0, 0, 0, 1}), loadFails},
+		{"truncated faces", plyBinary(binary.LittleEndian, plyTriangleHeader, []float32{0, 0, 0, 2, 0, 0, 0, 3, 0}, uint8(3), uint32(0)), loadFails},
+
+		// counts in the header must not be trusted for allocations
+		{"huge ascii vertex count", edit(plyASCIIQuad, "vertex 4", "vertex 2000000000"), loadFails},
+		{"huge ascii face count", edit(plyASCIIQuad, "face 1", "face 2000000000"), loadFails},
+		{"huge binary vertex count", plyTriangle(binary.LittleEndian, "vertex 3", "vertex 2000000000"), loadFails},
+		{"huge binary face count", plyTriangle(binary.BigEndian, "face 1", "face 2000000000"), loadFails},
+		{"huge empty element", edit(plyASCIIQuad, "element face 1", "element other 2000000000\nelement face 1"), 2},
+	})
+}
+
+func TestLoadPLYVertices(t *testing.T) {
+	// unused vertices are dropped
+	input := edit(plyASCIIQuad, "vertex 4", "vertex 5", "0 1 0 255\n", "0 1 0 255\n9 9 9 0\n")
+	data, err := LoadPLYReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(data.Buffer) / 3; n != 4 {
+		t.Errorf("got %d vertices, want 4", n)
+	}
+
+	data, err = LoadPLYReader(strings.NewReader(plyTriangle(binary.BigEndian)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := data.Box.Size(); size.X != 2 || size.Y != 3 || size.Z != 0 {
+		t.Errorf("got size %v, want 2 3 0", size)
+	}
+}
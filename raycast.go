@@ -0,0 +1,84 @@
+package meshview
+
+import (
+	"math"
+	"sync"
+
+	"github.com/fogleman/fauxgl"
+)
+
+// RayHit is where a ray meets a mesh: the triangle, the distance along the
+// ray and the barycentric coordinates of the point within the triangle, which
+// weight its second and third corners.
+type RayHit struct {
+	Triangle int
+	Distance float64
+	U, V     float64
+}
+
+// Triangle returns the corners of triangle i.
+func (data *MeshData) Triangle(i int) (fauxgl.Vector, fauxgl.Vector, fauxgl.Vector) {
+	i1, i2, i3 := i*3, i*3+1, i*3+2
+	if data.Indices != nil {
+		i1, i2, i3 = int(data.Indices[i1]), int(data.Indices[i2]), int(data.Indices[i3])
+	}
+	b := data.Buffer
+	p1 := fauxgl.V(float64(b[i1*3]), float64(b[i1*3+1]), float64(b[i1*3+2]))
+	p2 := fauxgl.V(float64(b[i2*3]), float64(b[i2*3+1]), float64(b[i2*3+2]))
+	p3 := fauxgl.V(float64(b[i3*3]), float64(b[i3*3+1]), float64(b[i3*3+2]))
+	return p1, p2, p3
+}
+
+// Raycast returns the nearest triangle hit by the ray, testing every
+// triangle. It is much slower than a BVH for large meshes, but there is
+// nothing to build first.
+func (data *MeshData) Raycast(origin, direction fauxgl.Vector) (RayHit, bool) {
+	best := RayHit{-1, math.Inf(1), 0, 0}
+	var mu sync.Mutex
+	parallel(data.TriangleCount(), func(i0, i1 int) {
+		hit := RayHit{-1, math.Inf(1), 0, 0}
+		for i := i0; i < i1; i++ {
+			p1, p2, p3 := data.Triangle(i)
+			t, u, v, ok := intersectTriangle(origin, direction, p1, p2, p3)
+			if ok && t < hit.Distance {
+				hit = RayHit{i, t, u, v}
+			}
+		}
+		mu.Lock()
+		if hit.Distance < best.Distance {
+			best = hit
+		}
+		mu.Unlock()
+	})
+	return best, best.Triangle >= 0
+}
+
+// intersectTriangle returns the distance along the ray to the triangle and
+// the barycentric coordinates of the hit, weighting p2 and p3. Both sides of
+// the triangle are hit.
+func intersectTriangle(origin, direction, p1, p2, p3 fauxgl.Vector) (float64, float64, float64, bool) {
+	const eps = 1e-12
+	e1 := p2.Sub(p1)
+	e2 := p3.Sub(p1)
+	p := direction.Cross(e2)
+	det := e1.Dot(p)
+	if math.Abs(det) < eps {
+		return 0, 0, 0, false
+	}
+	inv := 1 / det
+	s := origin.Sub(p1)
+	u := s.Dot(p) * inv
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+	q := s.Cross(e1)
+	v := direction.Dot(q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+	t := e2.Dot(q) * inv
+	if t < 0 {
+		return 0, 0, 0, false
+	}
+	return t, u, v, true
+}
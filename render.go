@@ -0,0 +1,111 @@
+package meshview
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/fogleman/fauxgl"
+)
+
+// The colors and light direction that Render draws with, which the viewer
+// uses too.
+var (
+	BackgroundColor = fauxgl.HexColor("D4D9DE")
+	MeshColor       = fauxgl.HexColor("5BACE3")
+	LightDirection  = fauxgl.V(1, -1.5, 1).Normalize()
+)
+
+type renderShader struct {
+	Matrix fauxgl.Matrix
+}
+
+func (shader *renderShader) Vertex(v fauxgl.Vertex) fauxgl.Vertex {
+	v.Output = shader.Matrix.MulPositionW(v.Position)
+	return v
+}
+
+func (shader *renderShader) Fragment(v fauxgl.Vertex) fauxgl.Color {
+	return v.Color
+}
+
+// Render draws the mesh with the software rasterizer from one of the preset
+// views (1-7), matching what the viewer shows in a window of the same
+// size. The image is rendered at supersample times the requested size and
+// scaled down to antialias edges.
+func Render(data *MeshData, view, width, height, supersample int) image.Image {
+	if supersample < 1 {
+		supersample = 1
+	}
+	w := width * supersample
+	h := height * supersample
+	aspect := float64(width) / float64(height)
+	camera := Camera(PresetView(view), fauxgl.Vector{}, fauxgl.Vector{}, 0, aspect, false)
+	matrix := camera.Mul(MeshTransform(data.Box))
+
+	// shade each triangle like the fragment shader, which derives a flat
+	// normal from the clip space position
+	buffer := data.TriangleBuffer()
+	triangles := make([]*fauxgl.Triangle, 0, len(buffer)/9)
+	for i := 0; i+8 < len(buffer); i += 9 {
+		b := buffer[i:]
+		p1 := fauxgl.V(float64(b[0]), float64(b[1]), float64(b[2]))
+		p2 := fauxgl.V(float64(b[3]), float64(b[4]), float64(b[5]))
+		p3 := fauxgl.V(float64(b[6]), float64(b[7]), float64(b[8]))
+		c1 := matrix.MulPositionW(p1).Vector()
+		c2 := matrix.MulPositionW(p2).Vector()
+		c3 := matrix.MulPositionW(p3).Vector()
+		n := c2.Sub(c1).Cross(c3.Sub(c1))
+		if n.Length() == 0 {
+			continue
+		}
+		diffuse := math.Max(0, n.Normalize().Dot(LightDirection))*0.9 + 0.15
+		c := MeshColor.MulScalar(diffuse)
+		c.A = 1
+		t := fauxgl.NewTriangleForPoints(p1, p2, p3)
+		t.V1.Color = c
+		t.V2.Color = c
+		t.V3.Color = c
+		triangles = append(triangles, t)
+	}
+
+	context := fauxgl.NewContext(w, h)
+	context.ClearColor = BackgroundColor
+	context.ClearColorBuffer()
+	context.FrontFace = fauxgl.FaceCCW
+	context.Cull = fauxgl.CullBack
+	context.Shader = &renderShader{matrix}
+	context.DrawTriangles(triangles)
+	return downsample(context.Image(), supersample)
+}
+
+func downsample(src image.Image, factor int) image.Image {
+	if factor <= 1 {
+		return src
+	}
+	bounds := src.Bounds()
+	w := bounds.Dx() / factor
+	h := bounds.Dy() / factor
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	n := uint32(factor * factor)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var r, g, b, a uint32
+			for dy := 0; dy < factor; dy++ {
+				for dx := 0; dx < factor; dx++ {
+					sx := bounds.Min.X + x*factor + dx
+					sy := bounds.Min.Y + y*factor + dy
+					c := color.NRGBAModel.Convert(src.At(sx, sy)).(color.NRGBA)
+					r += uint32(c.R)
+					g += uint32(c.G)
+					b += uint32(c.B)
+					a += uint32(c.A)
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n),
+			})
+		}
+	}
+	return dst
+}
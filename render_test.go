@@ -0,0 +1,21 @@
+package meshview
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	im := Render(cubeMesh(), 1, 64, 48, 2)
+	if size := im.Bounds().Size(); size.X != 64 || size.Y != 48 {
+		t.Fatalf("got size %v, want 64x48", size)
+	}
+	background := color.NRGBAModel.Convert(BackgroundColor.NRGBA())
+	if c := color.NRGBAModel.Convert(im.At(0, 0)); c != background {
+		t.Errorf("got corner %v, want the background %v", c, background)
+	}
+	c := color.NRGBAModel.Convert(im.At(32, 24)).(color.NRGBA)
+	if c == background || c.B <= c.R {
+		t.Errorf("got center %v, want the mesh color", c)
+	}
+}
@@ -2,17 +2,23 @@ package meshview
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/binary"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
-	"runtime"
 	"strconv"
 	"strings"
-	"sync"
 )
 
 func LoadSTL(path string) (*MeshData, error) {
+	return loadSTLFile(context.Background(), path, false)
+}
+
+func loadSTLFile(ctx context.Context, path string, lenient bool) (*MeshData, error) {
 	// open file
 	file, err := os.Open(path)
 	if err != nil {
@@ -26,73 +32,106 @@ func LoadSTL(path string) (*MeshData, error) {
 		return nil, err
 	}
 
+	return loadSTL(contextFile{ctx, file}, info.Size(), path, lenient)
+}
+
+// LoadSTLReader loads an ascii or binary stl from r. The whole input is
+// read into memory first, since binary stl is detected by its size.
+func LoadSTLReader(r io.Reader) (*MeshData, error) {
+	return loadSTLReader(r, "", false)
+}
+
+func loadSTLReader(r io.Reader, name string, lenient bool) (*MeshData, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return loadSTL(bytes.NewReader(buf), int64(len(buf)), name, lenient)
+}
+
+func loadSTL(file io.ReadSeeker, size int64, name string, lenient bool) (*MeshData, error) {
 	// read header, get expected binary size
 	type STLHeader struct {
 		_     [80]uint8
 		Count uint32
 	}
+	// files too short for the header can only be ascii
 	header := STLHeader{}
-	if err := binary.Read(file, binary.LittleEndian, &header); err != nil {
+	err := binary.Read(file, binary.LittleEndian, &header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return nil, err
 	}
 	expectedSize := int64(header.Count)*50 + 84
 
 	// parse ascii or binary stl
-	if info.Size() == expectedSize {
+	if err == nil && size == expectedSize {
 		return loadSTLB(file, int(header.Count))
 	} else {
 		// rewind to start of file
-		_, err = file.Seek(0, 0)
+		_, err := file.Seek(0, 0)
 		if err != nil {
 			return nil, err
 		}
-		return loadSTLA(file)
+		return loadSTLA(file, name, lenient)
 	}
 }
 
-func loadSTLA(file *os.File) (*MeshData, error) {
+func loadSTLA(file io.Reader, name string, lenient bool) (*MeshData, error) {
+	report := parseReport{Path: name, Lenient: lenient}
 	var data []float32
-	var x1, y1, z1, x2, y2, z2, x3, y3, z3 float32
-	i := 0
+	var facet [9]float32
+	n := 0
+	bad := false
+	line := 0
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if len(line) < 12 || line[0] != 'v' {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
 			continue
 		}
-		fields := strings.Fields(line[7:])
-		if len(fields) != 3 {
-			continue
-		}
-		x, _ := strconv.ParseFloat(fields[0], 32)
-		y, _ := strconv.ParseFloat(fields[1], 32)
-		z, _ := strconv.ParseFloat(fields[2], 32)
-		switch i % 3 {
-		case 0:
-			x1 = float32(x)
-			y1 = float32(y)
-			z1 = float32(z)
-		case 1:
-			x2 = float32(x)
-			y2 = float32(y)
-			z2 = float32(z)
-		case 2:
-			x3 = float32(x)
-			y3 = float32(y)
-			z3 = float32(z)
-			data = append(data, []float32{x1, y1, z1, x2, y2, z2, x3, y3, z3}...)
+		switch fields[0] {
+		case "vertex":
+			x, y, z, err := parseVector(fields[1:])
+			if err != nil {
+				if err := report.add(line, "vertex: %v", err); err != nil {
+					return nil, err
+				}
+				bad = true
+			}
+			facet[n*3+0] = x
+			facet[n*3+1] = y
+			facet[n*3+2] = z
+			n++
+			if n == 3 {
+				if !bad {
+					data = append(data, facet[:]...)
+				}
+				n = 0
+				bad = false
+			}
+		case "endloop":
+			if n != 0 {
+				if err := report.add(line, "facet has %d vertices, expected 3", n); err != nil {
+					return nil, err
+				}
+				n = 0
+				bad = false
+			}
 		}
-		i++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 	box := boxForData(data)
-	return &MeshData{data, box}, scanner.Err()
+	return &MeshData{Buffer: data, Box: box, Warnings: report.Warnings}, nil
 }
 
 func makeFloat(b []byte) float32 {
 	return math.Float32frombits(binary.LittleEndian.Uint32(b))
 }
 
-func loadSTLB(file *os.File, count int) (*MeshData, error) {
+func loadSTLB(file io.Reader, count int) (*MeshData, error) {
 	buf := make([]byte, count*50)
 	_, err := io.ReadFull(file, buf)
 	if err != nil {
@@ -100,41 +139,102 @@ func loadSTLB(file *os.File, count int) (*MeshData, error) {
 	}
 
 	data := make([]float32, count*9)
-	wn := runtime.NumCPU() - 1
-	if wn < 1 {
-		wn = 1
-	}
-	var wg sync.WaitGroup
-	for wi := 0; wi < wn; wi++ {
-		wg.Add(1)
-		go func(wi int) {
-			n := count / wn
-			if count%wn > 0 {
-				n++
-			}
-			i0 := n * wi
-			i1 := i0 + n
-			if i1 > count {
-				i1 = count
-			}
-			for i := i0; i < i1; i++ {
-				j := i * 9
-				b := buf[i*50+12:]
-				data[j+0] = makeFloat(b[0:])
-				data[j+1] = makeFloat(b[4:])
-				data[j+2] = makeFloat(b[8:])
-				data[j+3] = makeFloat(b[12:])
-				data[j+4] = makeFloat(b[16:])
-				data[j+5] = makeFloat(b[20:])
-				data[j+6] = makeFloat(b[24:])
-				data[j+7] = makeFloat(b[28:])
-				data[j+8] = makeFloat(b[32:])
-			}
-			wg.Done()
-		}(wi)
-	}
-	wg.Wait()
+	parallel(count, func(i0, i1 int) {
+		for i := i0; i < i1; i++ {
+			j := i * 9
+			b := buf[i*50+12:]
+			data[j+0] = makeFloat(b[0:])
+			data[j+1] = makeFloat(b[4:])
+			data[j+2] = makeFloat(b[8:])
+			data[j+3] = makeFloat(b[12:])
+			data[j+4] = makeFloat(b[16:])
+			data[j+5] = makeFloat(b[20:])
+			data[j+6] = makeFloat(b[24:])
+			data[j+7] = makeFloat(b[28:])
+			data[j+8] = makeFloat(b[32:])
+		}
+	})
 
 	box := boxForData(data)
-	return &MeshData{data, box}, nil
+	return &MeshData{Buffer: data, Box: box}, nil
+}
+
+func SaveSTL(path string, data *MeshData, ascii bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	if ascii {
+		err = writeSTLA(writer, data)
+	} else {
+		err = writeSTLB(writer, data)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func triangleNormal(b []float32) (float32, float32, float32) {
+	ux, uy, uz := b[3]-b[0], b[4]-b[1], b[5]-b[2]
+	vx, vy, vz := b[6]-b[0], b[7]-b[1], b[8]-b[2]
+	nx := float64(uy*vz - uz*vy)
+	ny := float64(uz*vx - ux*vz)
+	nz := float64(ux*vy - uy*vx)
+	d := math.Sqrt(nx*nx + ny*ny + nz*nz)
+	if d == 0 {
+		return 0, 0, 0
+	}
+	return float32(nx / d), float32(ny / d), float32(nz / d)
+}
+
+func formatFloat(x float32) string {
+	return strconv.FormatFloat(float64(x), 'g', -1, 32)
+}
+
+func writeSTLA(w *bufio.Writer, data *MeshData) error {
+	buffer := data.TriangleBuffer()
+	w.WriteString("solid meshview\n")
+	for i := 0; i+8 < len(buffer); i += 9 {
+		b := buffer[i : i+9]
+		nx, ny, nz := triangleNormal(b)
+		fmt.Fprintf(w, "facet normal %s %s %s\n", formatFloat(nx), formatFloat(ny), formatFloat(nz))
+		w.WriteString("  outer loop\n")
+		for j := 0; j < 9; j += 3 {
+			fmt.Fprintf(w, "    vertex %s %s %s\n", formatFloat(b[j]), formatFloat(b[j+1]), formatFloat(b[j+2]))
+		}
+		w.WriteString("  endloop\n")
+		w.WriteString("endfacet\n")
+	}
+	_, err := w.WriteString("endsolid meshview\n")
+	return err
+}
+
+func writeSTLB(w *bufio.Writer, data *MeshData) error {
+	buffer := data.TriangleBuffer()
+	count := len(buffer) / 9
+	header := make([]byte, 84)
+	binary.LittleEndian.PutUint32(header[80:], uint32(count))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	record := make([]byte, 50)
+	for i := 0; i < count; i++ {
+		b := buffer[i*9 : i*9+9]
+		nx, ny, nz := triangleNormal(b)
+		binary.LittleEndian.PutUint32(record[0:], math.Float32bits(nx))
+		binary.LittleEndian.PutUint32(record[4:], math.Float32bits(ny))
+		binary.LittleEndian.PutUint32(record[8:], math.Float32bits(nz))
+		for j, x := range b {
+			binary.LittleEndian.PutUint32(record[12+j*4:], math.Float32bits(x))
+		}
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
 }
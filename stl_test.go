@@ -0,0 +1,56 @@
+package meshview
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+const stlASCIIQuad = `solid quad
+facet normal 0 0 1
+outer loop
+vertex 0 0 0
+vertex 1 0 0
+vertex 1 1 0
+endloop
+endfacet
+facet normal 0 0 1
+outer loop
+vertex 0 0 0
+vertex 1 1 0
+vertex 0 1 0
+endloop
+endfacet
+endsolid quad
+`
+
+// stlBinary returns a binary stl with the given header text and one facet
+// for each nine coordinates.
+func stlBinary(header string, coordinates ...float32) string {
+	h := make([]byte, 80)
+	copy(h, header)
+	count := len(coordinates) / 9
+	values := []interface{}{h, uint32(count)}
+	for i := 0; i < count; i++ {
+		values = append(values, [3]float32{}, coordinates[i*9:i*9+9], uint16(0))
+	}
+	return pack(binary.LittleEndian, values...)
+}
+
+func TestLoadSTL(t *testing.T) {
+	testLoader(t, LoadSTLReader, []loaderTest{
+		{"ascii", stlASCIIQuad, 2},
+		{"crlf", edit(stlASCIIQuad, "\n", "\r\n"), 2},
+		{"binary", stlBinary("binary", 0, 0, 0, 1, 0, 0, 1, 1, 0), 1},
+		{"binary named solid", stlBinary("solid quad", 0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 0, 0, 1, 1, 0, 0, 1, 0), 2},
+		{"short ascii", "solid\nendsolid\n", 0},
+	})
+}
+
+func TestLoadSTLMalformed(t *testing.T) {
+	testLineErrors(t, loadSTLReader, []lineTest{
+		{"bad number", edit(stlASCIIQuad, "vertex 1 0 0", "vertex 1 zero 0"), 5, 1},
+		{"missing coordinate", edit(stlASCIIQuad, "vertex 0 1 0", "vertex 0 1"), 13, 1},
+		{"two vertices", edit(stlASCIIQuad, "vertex 1 1 0\nendloop", "endloop"), 6, 1},
+		{"four vertices", edit(stlASCIIQuad, "vertex 1 1 0\nendloop", "vertex 1 1 0\nvertex 1 1 1\nendloop"), 8, 2},
+	})
+}
@@ -1,25 +1,327 @@
 package meshview
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/fogleman/fauxgl"
 )
 
+// LoadOptions configures LoadMeshContext. The zero value welds identical
+// vertices and fails on the first malformed record.
+type LoadOptions struct {
+	// WeldEpsilon is the distance within which the vertices of formats that
+	// store triangle soup, such as STL, are merged to build an index buffer.
+	// Zero merges only identical vertices and a negative value disables
+	// welding.
+	WeldEpsilon float64
+
+	// Lenient makes the text loaders skip malformed records, collecting them
+	// in MeshData.Warnings, rather than failing on the first one.
+	Lenient bool
+
+	// Warnings, if not nil, is where the number of skipped records and the
+	// first of them are printed after a lenient load.
+	Warnings io.Writer
+}
+
+// ParseError describes a malformed record in a text mesh file.
+type ParseError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Message)
+}
+
+// parseReport collects parse errors, returning them unless Lenient is set.
+type parseReport struct {
+	Path     string
+	Lenient  bool
+	Warnings []error
+}
+
+func (r *parseReport) add(line int, format string, a ...interface{}) error {
+	err := &ParseError{r.Path, line, fmt.Sprintf(format, a...)}
+	if !r.Lenient {
+		return err
+	}
+	r.Warnings = append(r.Warnings, err)
+	return nil
+}
+
+func parseVector(fields []string) (float32, float32, float32, error) {
+	if len(fields) < 3 {
+		return 0, 0, 0, fmt.Errorf("expected 3 coordinates, got %d", len(fields))
+	}
+	var v [3]float32
+	for i := range v {
+		x, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid number %q", fields[i])
+		}
+		v[i] = float32(x)
+	}
+	return v[0], v[1], v[2], nil
+}
+
+// LoadMesh loads the mesh at path, choosing the format by extension, or by
+// content if the extension is not recognized. A path of "-" reads stdin.
+// Files compressed with gzip or zstd are decompressed, and a zip archive
+// loads all of its meshes, or a single one given a path like
+// archive.zip/model.stl.
 func LoadMesh(path string) (*MeshData, error) {
+	return LoadMeshContext(context.Background(), path, nil)
+}
+
+// LoadMeshOptions is like LoadMesh, but loads with the given options, or the
+// defaults if options is nil.
+func LoadMeshOptions(path string, options *LoadOptions) (*MeshData, error) {
+	return LoadMeshContext(context.Background(), path, options)
+}
+
+// LoadMeshContext is like LoadMeshOptions, but gives up with ctx.Err() once
+// ctx is cancelled. STL, OBJ and PLY files and compressed, archived or piped
+// input check for cancellation as they are read; other formats only between
+// loading steps.
+func LoadMeshContext(ctx context.Context, path string, options *LoadOptions) (*MeshData, error) {
+	if options == nil {
+		options = &LoadOptions{}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	loader := meshLoader{ctx, options}
+	var data *MeshData
+	var err error
+	archive, entry := SplitZipPath(path)
+	switch {
+	case path == "-":
+		data, err = loader.reader(contextReader{ctx, os.Stdin}, "", gltfDir("."))
+	case entry != "":
+		data, err = loader.zipFile(archive, entry)
+	default:
+		data, err = loader.file(path)
+	}
+	return finishMeshContext(ctx, data, err, options)
+}
+
+// meshLoader loads meshes of any format for one LoadMeshContext call,
+// including those inside compressed files and archives.
+type meshLoader struct {
+	ctx     context.Context
+	options *LoadOptions
+}
+
+func (loader *meshLoader) file(path string) (*MeshData, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".stl":
+		return loadSTLFile(loader.ctx, path, loader.options.Lenient)
+	case ".obj":
+		return loadOBJFile(loader.ctx, path, loader.options.Lenient)
+	case ".ply":
+		return loadPLYFile(loader.ctx, path)
+	case ".gltf", ".glb":
+		return LoadGLTF(path)
+	case ".3mf":
+		return Load3MF(path)
+	case ".zip":
+		return loader.zipFile(path, "")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return loader.reader(contextFile{loader.ctx, file}, path, gltfDir(filepath.Dir(path)))
+}
+
+// reader loads a mesh from r, choosing the format by the extension of name
+// if it has a known one, or by content otherwise. External gltf buffers are
+// read with resolve.
+func (loader *meshLoader) reader(r io.Reader, name string, resolve gltfResolver) (*MeshData, error) {
+	reader := bufio.NewReader(r)
+	ext := strings.ToLower(filepath.Ext(name))
+	if !meshExtensions[ext] && !compressedExtensions[ext] {
+		header, _ := reader.Peek(512)
+		ext = sniffFormat(header)
+		name = ""
+	}
+	switch ext {
+	case ".stl":
+		return loadSTLReader(reader, name, loader.options.Lenient)
+	case ".obj":
+		return loadOBJ(reader, name, loader.options.Lenient)
+	case ".ply":
+		return LoadPLYReader(reader)
+	case ".gltf", ".glb":
+		return loadGLTFReader(reader, resolve)
+	case ".3mf":
+		return Load3MFReader(reader)
+	case ".gz", ".zst", ".zip":
+		return loader.compressed(reader, ext, strings.TrimSuffix(name, filepath.Ext(name)), resolve)
+	}
+	return nil, fmt.Errorf("unrecognized mesh format")
+}
+
+// contextFile fails reads once its context is cancelled, so that loaders
+// reading from it stop soon after.
+type contextFile struct {
+	ctx context.Context
+	*os.File
+}
+
+func (f contextFile) Read(p []byte) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return f.File.Read(p)
+}
+
+// contextReader is like contextFile for readers that cannot seek, such as
+// stdin and archive entries.
+type contextReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.Reader.Read(p)
+}
+
+// LoadMeshReader loads a mesh from r, detecting the format from its content.
+// External gltf buffers are resolved relative to the current directory.
+func LoadMeshReader(r io.Reader) (*MeshData, error) {
+	loader := meshLoader{context.Background(), &LoadOptions{}}
+	data, err := loader.reader(r, "", gltfDir("."))
+	return finishMesh(data, err, loader.options)
+}
+
+func finishMeshContext(ctx context.Context, data *MeshData, err error, options *LoadOptions) (*MeshData, error) {
+	// a cancelled load fails with ctx.Err(), whatever error the loader
+	// stopped with
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	data, err = finishMesh(data, err, options)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return data, err
+}
+
+// finishMesh welds a loaded mesh and reports any records that were skipped,
+// so that every loader reports them the same way.
+func finishMesh(data *MeshData, err error, options *LoadOptions) (*MeshData, error) {
+	if err != nil {
+		return nil, err
+	}
+	n := len(data.Warnings)
+	if data.TriangleCount() == 0 {
+		// say why, if every record was skipped
+		if n > 0 {
+			return nil, fmt.Errorf("no triangles, skipped %d malformed records, first: %v", n, data.Warnings[0])
+		}
+		return nil, fmt.Errorf("no triangles")
+	}
+	if n > 0 && options.Warnings != nil {
+		fmt.Fprintf(options.Warnings, "skipped %d malformed records, first: %v\n", n, data.Warnings[0])
+	}
+	if data.Indices == nil && options.WeldEpsilon >= 0 {
+		data.Weld(options.WeldEpsilon)
+	}
+	return data, nil
+}
+
+var meshExtensions = map[string]bool{
+	".stl": true, ".obj": true, ".ply": true, ".gltf": true, ".glb": true, ".3mf": true,
+}
+
+var compressedExtensions = map[string]bool{
+	".gz": true, ".zst": true, ".zip": true,
+}
+
+var objKeywords = map[string]bool{
+	"v": true, "vn": true, "vt": true, "vp": true, "f": true, "l": true,
+	"p": true, "o": true, "g": true, "s": true, "mtllib": true, "usemtl": true,
+}
+
+// sniffFormat guesses a mesh format from the first bytes of a file,
+// returning the matching extension or an empty string.
+func sniffFormat(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("ply\n")), bytes.HasPrefix(header, []byte("ply\r\n")):
+		return ".ply"
+	case bytes.HasPrefix(header, []byte("glTF")):
+		return ".glb"
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		return ".zip"
+	case bytes.HasPrefix(header, []byte("\x1f\x8b")):
+		return ".gz"
+	case bytes.HasPrefix(header, []byte("\x28\xb5\x2f\xfd")):
+		return ".zst"
+	}
+	text := bytes.TrimLeft(header, " \t\r\n")
+	switch {
+	case bytes.HasPrefix(text, []byte("{")):
+		return ".gltf"
+	case bytes.HasPrefix(text, []byte("solid")):
+		return ".stl"
+	}
+	if bytes.IndexByte(header, 0) < 0 {
+		// text, so look for a known obj keyword
+		for _, line := range bytes.Split(text, []byte("\n")) {
+			fields := strings.Fields(string(line))
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			if objKeywords[fields[0]] {
+				return ".obj"
+			}
+			return ""
+		}
+		return ""
+	}
+	if len(header) >= 84 {
+		return ".stl"
+	}
+	return ""
+}
+
+func SaveMesh(path string, data *MeshData, ascii bool) error {
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
 	case ".stl":
-		return LoadSTL(path)
+		return SaveSTL(path, data, ascii)
 	case ".obj":
-		return LoadOBJ(path)
+		return SaveOBJ(path, data)
+	case ".ply":
+		return SavePLY(path, data, ascii)
 	}
-	return nil, fmt.Errorf("unrecognized mesh extension: %s", ext)
+	return fmt.Errorf("unrecognized mesh extension: %s", ext)
 }
 
 func boxForData(data []float32) fauxgl.Box {
+	if len(data) < 3 {
+		return fauxgl.Box{}
+	}
 	minx := data[0]
 	maxx := data[0]
 	miny := data[1]
@@ -53,3 +355,33 @@ func boxForData(data []float32) fauxgl.Box {
 	max := fauxgl.Vector{float64(maxx), float64(maxy), float64(maxz)}
 	return fauxgl.Box{min, max}
 }
+
+// parallel splits the range [0, count) into one chunk per worker and calls fn
+// for each chunk concurrently, returning when all chunks are done.
+func parallel(count int, fn func(i0, i1 int)) {
+	wn := runtime.NumCPU() - 1
+	if wn < 1 {
+		wn = 1
+	}
+	n := count / wn
+	if count%wn > 0 {
+		n++
+	}
+	var wg sync.WaitGroup
+	for wi := 0; wi < wn; wi++ {
+		i0 := n * wi
+		i1 := i0 + n
+		if i1 > count {
+			i1 = count
+		}
+		if i0 >= i1 {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			fn(i0, i1)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
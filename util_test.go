@@ -0,0 +1,308 @@
+package meshview
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadFails marks a loaderTest whose input must fail to load.
+const loadFails = -1
+
+// loaderTest is an input for a loader and the number of triangles it should
+// load, or loadFails.
+type loaderTest struct {
+	name      string
+	input     string
+	triangles int
+}
+
+// testLoader loads each input, checking that malformed ones fail with an
+// error rather than a panic.
+func testLoader(t *testing.T, load func(io.Reader) (*MeshData, error), tests []loaderTest) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := load(strings.NewReader(test.input))
+			if test.triangles == loadFails {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := data.TriangleCount(); n != test.triangles {
+				t.Errorf("got %d triangles, want %d", n, test.triangles)
+			}
+		})
+	}
+}
+
+// lineTest is a text file with its first malformed record on line, which a
+// lenient load skips, leaving triangles.
+type lineTest struct {
+	name      string
+	input     string
+	line      int
+	triangles int
+}
+
+// testLineErrors checks that load reports the first malformed record of each
+// input with the file name and line, and skips it when lenient.
+func testLineErrors(t *testing.T, load func(r io.Reader, name string, lenient bool) (*MeshData, error), tests []lineTest) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := load(strings.NewReader(test.input), "model", false)
+			if e, ok := err.(*ParseError); !ok || e.Path != "model" || e.Line != test.line {
+				t.Errorf("got %v, want an error at model:%d", err, test.line)
+			}
+			data, err := load(strings.NewReader(test.input), "model", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := data.TriangleCount(); n != test.triangles {
+				t.Errorf("lenient: got %d triangles, want %d", n, test.triangles)
+			}
+			if len(data.Warnings) == 0 {
+				t.Error("lenient: got no warnings")
+			} else if e, ok := data.Warnings[0].(*ParseError); !ok || e.Line != test.line {
+				t.Errorf("lenient: got first warning %v, want line %d", data.Warnings[0], test.line)
+			}
+		})
+	}
+}
+
+// edit returns fixture with each pair of old and new strings replaced, for
+// deriving malformed inputs from a valid one.
+func edit(fixture string, pairs ...string) string {
+	return strings.NewReplacer(pairs...).Replace(fixture)
+}
+
+// pack writes values in binary with the given byte order.
+func pack(order binary.ByteOrder, values ...interface{}) string {
+	var buf bytes.Buffer
+	for _, v := range values {
+		binary.Write(&buf, order, v)
+	}
+	return buf.String()
+}
+
+// zipFixture returns a zip archive of the given entries, as pairs of names
+// and contents.
+func zipFixture(entries ...string) string {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(entries); i += 2 {
+		f, _ := w.Create(entries[i])
+		f.Write([]byte(entries[i+1]))
+	}
+	w.Close()
+	return buf.String()
+}
+
+// tempFiles writes files, as pairs of names and contents, to a new
+// directory, returning it and a function that removes it.
+func tempFiles(t *testing.T, files ...string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "meshview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(files); i += 2 {
+		path := filepath.Join(dir, filepath.FromSlash(files[i]))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(files[i+1]), 0644); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// cubeMesh returns the cube from -1 to 1 as indexed triangles.
+func cubeMesh() *MeshData {
+	buffer := []float32{
+		-1, -1, -1, 1, -1, -1, 1, 1, -1, -1, 1, -1,
+		-1, -1, 1, 1, -1, 1, 1, 1, 1, -1, 1, 1,
+	}
+	indices := []uint32{
+		0, 2, 1, 0, 3, 2, // bottom
+		4, 5, 6, 4, 6, 7, // top
+		0, 1, 5, 0, 5, 4, // front
+		2, 3, 7, 2, 7, 6, // back
+		1, 2, 6, 1, 6, 5, // right
+		0, 4, 7, 0, 7, 3, // left
+	}
+	return &MeshData{Buffer: buffer, Indices: indices, Box: boxForData(buffer)}
+}
+
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		ext    string
+	}{
+		{"ply", plyASCIIQuad, ".ply"},
+		{"ply crlf", "ply\r\nformat ascii 1.0\r\n", ".ply"},
+		{"glb", glb(2, 0), ".glb"},
+		{"gltf", "  " + gltfEmbedded(), ".gltf"},
+		{"zip", zipFixture("a.stl", stlASCIIQuad), ".zip"},
+		{"gzip", "\x1f\x8b\x08\x00", ".gz"},
+		{"zstd", "\x28\xb5\x2f\xfd\x00", ".zst"},
+		{"ascii stl", stlASCIIQuad, ".stl"},
+		{"binary stl", stlBinary("binary", 0, 0, 0, 1, 0, 0, 1, 1, 0), ".stl"},
+		{"obj", objQuad, ".obj"},
+		{"obj without comments", "\nv 0 0 0\n", ".obj"},
+		{"text", "hello world\n", ""},
+		{"short binary", "\x00\x01\x02", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ext := sniffFormat([]byte(test.header)); ext != test.ext {
+				t.Errorf("got %q, want %q", ext, test.ext)
+			}
+		})
+	}
+}
+
+func TestLoadMeshReader(t *testing.T) {
+	testLoader(t, LoadMeshReader, []loaderTest{
+		{"stl", stlASCIIQuad, 2},
+		{"binary stl", stlBinary("binary", 0, 0, 0, 1, 0, 0, 1, 1, 0), 1},
+		{"obj", objQuad, 2},
+		{"ply", plyASCIIQuad, 2},
+		{"gltf", gltfEmbedded(), 1},
+		{"glb", glb(2, 0), 1},
+		{"3mf", zipFixture("3D/3dmodel.model", threeMFDocument()), 3},
+		{"empty", "", loadFails},
+		{"unknown", "hello world\n", loadFails},
+		{"no triangles", "solid empty\nendsolid empty\n", loadFails},
+	})
+}
+
+func TestSaveMesh(t *testing.T) {
+	dir, cleanup := tempFiles(t)
+	defer cleanup()
+	buffer := []float32{
+		0, 0, 0, 1, 0, 0, 1, 1, 0,
+		0, 0, 0, 1, 1, 0, 0, 1, 2,
+	}
+	data := &MeshData{Buffer: buffer, Box: boxForData(buffer)}
+	tests := []struct {
+		name  string
+		ascii bool
+	}{
+		{"quad.stl", false},
+		{"quad-ascii.stl", true},
+		{"quad.obj", false},
+		{"quad.ply", false},
+		{"quad-ascii.ply", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			if err := SaveMesh(path, data, test.ascii); err != nil {
+				t.Fatal(err)
+			}
+			saved, err := LoadMesh(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := saved.TriangleCount(); n != data.TriangleCount() {
+				t.Errorf("got %d triangles, want %d", n, data.TriangleCount())
+			}
+			if saved.Box != data.Box {
+				t.Errorf("got box %v, want %v", saved.Box, data.Box)
+			}
+		})
+	}
+	if err := SaveMesh(filepath.Join(dir, "quad.gltf"), data, false); err == nil {
+		t.Error("expected an error saving gltf")
+	}
+}
+
+func TestLoadMeshWarnings(t *testing.T) {
+	// the face using the malformed vertex is skipped too
+	dir, cleanup := tempFiles(t,
+		"one.obj", edit(objQuad, "v 1 1 0", "v 1 one 0", "f 1//1 2//1 3//1 4//1", "f 1//1 2//1 4//1"),
+		"none.obj", edit(objQuad, "v 1 1 0", "v 1 one 0"))
+	defer cleanup()
+
+	// strict loads fail on the first malformed record
+	if _, err := LoadMesh(filepath.Join(dir, "one.obj")); err == nil || !strings.Contains(err.Error(), "one.obj:4:") {
+		t.Errorf("got %v, want an error at one.obj:4", err)
+	}
+
+	// lenient loads print one summary
+	var warnings bytes.Buffer
+	options := &LoadOptions{Lenient: true, Warnings: &warnings}
+	data, err := LoadMeshOptions(filepath.Join(dir, "one.obj"), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := data.TriangleCount(); n != 1 {
+		t.Errorf("got %d triangles, want 1", n)
+	}
+	if got := warnings.String(); strings.Count(got, "\n") != 1 || !strings.HasPrefix(got, "skipped 1 malformed records") {
+		t.Errorf("got warnings %q, want one summary line", got)
+	}
+
+	// and say why nothing loaded if every record was skipped
+	_, err = LoadMeshOptions(filepath.Join(dir, "none.obj"), options)
+	if err == nil || !strings.Contains(err.Error(), "none.obj:4:") {
+		t.Errorf("got %v, want no triangles with the first malformed record", err)
+	}
+}
+
+func TestLoadMeshCancelled(t *testing.T) {
+	dir, cleanup := tempFiles(t,
+		"quad.stl", stlASCIIQuad,
+		"parts.zip", zipFixture("quad.stl", stlASCIIQuad))
+	defer cleanup()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadMeshContext(ctx, filepath.Join(dir, "quad.stl"), nil); err != context.Canceled {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+
+	// loads cancelled partway stop at their next read
+	loader := meshLoader{ctx, &LoadOptions{}}
+	tests := []struct {
+		name string
+		load func() (*MeshData, error)
+	}{
+		{"file", func() (*MeshData, error) {
+			return loader.file(filepath.Join(dir, "quad.stl"))
+		}},
+		{"reader", func() (*MeshData, error) {
+			return loader.reader(contextReader{ctx, strings.NewReader(plyASCIIQuad)}, "", gltfDir("."))
+		}},
+		{"zip", func() (*MeshData, error) {
+			return loader.zipFile(filepath.Join(dir, "parts.zip"), "")
+		}},
+		{"zip entry", func() (*MeshData, error) {
+			return loader.zipFile(filepath.Join(dir, "parts.zip"), "quad.stl")
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.load()
+			if err == nil {
+				t.Error("the load did not stop")
+			}
+			if _, err := finishMeshContext(ctx, data, err, loader.options); err != context.Canceled {
+				t.Errorf("got %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
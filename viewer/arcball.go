@@ -1,9 +1,11 @@
-package meshview
+package viewer
 
 import (
 	"math"
+	"time"
 
 	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
@@ -13,9 +15,13 @@ type Arcball struct {
 	Current     fauxgl.Vector
 	Rotation    fauxgl.Matrix
 	Translation fauxgl.Vector
+	Pivot       fauxgl.Vector
 	Scroll      float64
 	Rotate      bool
 	Pan         bool
+	Ortho       bool
+	Pick        PickFunc
+	clicked     time.Time
 }
 
 func NewArcball() Interactor {
@@ -38,6 +44,12 @@ func (a *Arcball) MouseButtonCallback(window *glfw.Window, button glfw.MouseButt
 	if button == glfw.MouseButton1 {
 		if action == glfw.Press {
 			if mods == 0 {
+				if isDoubleClick(&a.clicked) && a.Pick != nil {
+					if p, ok := a.Pick(window); ok {
+						a.Translation = movePivot(a.Rotation, a.Pivot, a.Translation, p, a.Scroll)
+						a.Pivot = p
+					}
+				}
 				v := arcballVector(window)
 				a.Start = v
 				a.Current = v
@@ -65,31 +77,25 @@ func (a *Arcball) MouseButtonCallback(window *glfw.Window, button glfw.MouseButt
 
 func (a *Arcball) KeyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
 	if action == glfw.Press && mods == 0 {
-		if key >= 49 && key <= 55 {
+		if key >= glfw.Key1 && key <= glfw.Key7 {
+			a.Rotation = meshview.PresetView(int(key - glfw.Key0))
 			a.Translation = fauxgl.Vector{}
+			a.Pivot = fauxgl.Vector{}
 			a.Scroll = 0
 		}
-		switch key {
-		case 49: //1
-			a.Rotation = fauxgl.Identity()
-		case 50:
-			a.Rotation = fauxgl.Identity().Rotate(fauxgl.V(0, 0, 1), math.Pi/2)
-		case 51:
-			a.Rotation = fauxgl.Identity().Rotate(fauxgl.V(0, 0, 1), math.Pi)
-		case 52:
-			a.Rotation = fauxgl.Identity().Rotate(fauxgl.V(0, 0, 1), -math.Pi/2)
-		case 53:
-			a.Rotation = fauxgl.Identity().Rotate(fauxgl.V(1, 0, 0), math.Pi/2)
-		case 54:
-			a.Rotation = fauxgl.Identity().Rotate(fauxgl.V(1, 0, 0), -math.Pi/2)
-		case 55:
-			a.Rotation = fauxgl.Identity().Rotate(fauxgl.V(1, 1, 0).Normalize(), -math.Pi/4).Rotate(fauxgl.V(0, 0, 1), math.Pi/4)
-		case 88: //X
-		}
 	}
 }
 
+func (a *Arcball) SetOrtho(ortho bool) {
+	a.Ortho = ortho
+}
+
 func (a *Arcball) ScrollCallback(window *glfw.Window, dx, dy float64) {
+	if a.Pick != nil && !a.Rotate && !a.Pan {
+		if p, ok := a.Pick(window); ok {
+			a.Translation = zoomToward(a.Rotation, a.Pivot, a.Translation, p, a.Scroll, dy)
+		}
+	}
 	a.Scroll += dy
 }
 
@@ -104,14 +110,22 @@ func (a *Arcball) Matrix(window *glfw.Window) fauxgl.Matrix {
 	if a.Pan {
 		t = t.Add(a.Current.Sub(a.Start))
 	}
-	s := math.Pow(0.98, a.Scroll)
-	m := fauxgl.Identity()
-	m = m.Scale(fauxgl.V(s, s, s))
-	m = r.Mul(m)
-	m = m.Translate(t)
-	m = m.LookAt(fauxgl.V(0, -3, 0), fauxgl.V(0, 0, 0), fauxgl.V(0, 0, 1))
-	m = m.Perspective(50, aspect, 0.1, 100)
-	return m
+	return meshview.Camera(r, a.Pivot, t, a.Scroll, aspect, a.Ortho)
+}
+
+// zoomToward returns the translation that keeps the point p in place on
+// screen as the zoom changes from scroll to scroll+dy.
+func zoomToward(r fauxgl.Matrix, pivot, t, p fauxgl.Vector, scroll, dy float64) fauxgl.Vector {
+	s0 := meshview.ZoomScale(scroll)
+	s1 := meshview.ZoomScale(scroll + dy)
+	return t.Add(r.MulDirection(p.Sub(pivot)).MulScalar(s0 - s1))
+}
+
+// movePivot returns the translation that keeps the view unchanged when the
+// pivot moves to p.
+func movePivot(r fauxgl.Matrix, pivot, t, p fauxgl.Vector, scroll float64) fauxgl.Vector {
+	s := meshview.ZoomScale(scroll)
+	return t.Add(r.MulDirection(p.Sub(pivot)).MulScalar(s))
 }
 
 func screenPosition(window *glfw.Window) fauxgl.Vector {
@@ -0,0 +1,129 @@
+package viewer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fogleman/meshview"
+	"github.com/fsnotify/fsnotify"
+)
+
+// browser steps through the mesh files in a directory, or matching a glob,
+// in sorted order.
+type browser struct {
+	Pattern string
+	Files   []string
+	Index   int
+}
+
+// isBrowsePattern reports whether path names a directory or, if nothing
+// exists at path, is a glob.
+func isBrowsePattern(path string) bool {
+	info, err := os.Stat(path)
+	if err == nil {
+		return info.IsDir()
+	}
+	return strings.ContainsAny(path, "*?[")
+}
+
+func newBrowser(pattern string) (*browser, error) {
+	b := &browser{Pattern: pattern}
+	if err := b.list(); err != nil {
+		return nil, err
+	}
+	if len(b.Files) == 0 {
+		return nil, fmt.Errorf("no mesh files in %s", pattern)
+	}
+	return b, nil
+}
+
+// list finds the files again, keeping the current one selected if it is
+// still there.
+func (b *browser) list() error {
+	var files []string
+	if info, err := os.Stat(b.Pattern); err == nil && info.IsDir() {
+		infos, err := ioutil.ReadDir(b.Pattern)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			files = append(files, filepath.Join(b.Pattern, info.Name()))
+		}
+	} else {
+		matches, err := filepath.Glob(b.Pattern)
+		if err != nil {
+			return err
+		}
+		files = matches
+	}
+
+	current := b.current()
+	b.Files = b.Files[:0]
+	for _, path := range files {
+		if !meshview.IsMeshName(path) && strings.ToLower(filepath.Ext(path)) != ".zip" {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		b.Files = append(b.Files, path)
+	}
+	sort.Strings(b.Files)
+
+	if i := sort.SearchStrings(b.Files, current); i < len(b.Files) && b.Files[i] == current {
+		b.Index = i
+	} else if b.Index >= len(b.Files) {
+		b.Index = len(b.Files) - 1
+	}
+	if b.Index < 0 {
+		b.Index = 0
+	}
+	return nil
+}
+
+func (b *browser) current() string {
+	if b.Index >= len(b.Files) {
+		return ""
+	}
+	return b.Files[b.Index]
+}
+
+// step moves by n files, wrapping around at either end, and returns the new
+// file.
+func (b *browser) step(n int) string {
+	if len(b.Files) == 0 {
+		return ""
+	}
+	b.Index = ((b.Index+n)%len(b.Files) + len(b.Files)) % len(b.Files)
+	return b.current()
+}
+
+// dir returns the directory to watch for new files, which is empty for
+// globs with wildcards in their directory.
+func (b *browser) dir() string {
+	if info, err := os.Stat(b.Pattern); err == nil && info.IsDir() {
+		return filepath.Clean(b.Pattern)
+	}
+	dir := filepath.Dir(b.Pattern)
+	if strings.ContainsAny(dir, "*?[") {
+		return ""
+	}
+	return dir
+}
+
+// affects reports whether event adds or removes a file in the directory.
+func (b *browser) affects(event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+		return false
+	}
+	dir := b.dir()
+	return dir != "" && filepath.Dir(filepath.Clean(event.Name)) == dir
+}
+
+func (b *browser) title() string {
+	return fmt.Sprintf("%s (%d of %d)", b.current(), b.Index+1, len(b.Files))
+}
@@ -0,0 +1,78 @@
+package viewer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempDir creates the named files, or directories for names ending in a
+// slash, in a new directory, returning it and a function that removes it.
+func tempDir(t *testing.T, names ...string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "meshview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if name[len(name)-1] == '/' {
+			err = os.Mkdir(path, 0755)
+		} else {
+			err = ioutil.WriteFile(path, nil, 0644)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestBrowser(t *testing.T) {
+	dir, cleanup := tempDir(t, "c.stl", "a.OBJ", "b.ply.gz", "d.zip", "notes.txt", "e.stl/")
+	defer cleanup()
+	b, err := newBrowser(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mesh files in sorted order, wrapping around at either end
+	want := []string{"a.OBJ", "b.ply.gz", "c.stl", "d.zip"}
+	if len(b.Files) != len(want) {
+		t.Fatalf("got %v, want %v", b.Files, want)
+	}
+	for _, step := range []int{0, 1, 1, 1, 1, -1, -1, -5} {
+		i := b.Index
+		got := filepath.Base(b.step(step))
+		i = ((i+step)%len(want) + len(want)) % len(want)
+		if got != want[i] {
+			t.Errorf("step %d: got %s, want %s", step, got, want[i])
+		}
+	}
+
+	// relisting keeps the current file
+	current := b.current()
+	if err := ioutil.WriteFile(filepath.Join(dir, "0.stl"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.list(); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Files) != len(want)+1 || b.current() != current {
+		t.Errorf("got %s of %v, want %s", b.current(), b.Files, current)
+	}
+
+	// globs match names, not formats
+	b, err = newBrowser(filepath.Join(dir, "*.stl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Files) != 2 || b.title() != filepath.Join(dir, "0.stl")+" (1 of 2)" {
+		t.Errorf("got %s, want 0.stl (1 of 2)", b.title())
+	}
+	if _, err := newBrowser(filepath.Join(dir, "*.3mf")); err == nil {
+		t.Error("expected an error for no matches")
+	}
+}
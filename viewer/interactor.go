@@ -0,0 +1,256 @@
+package viewer
+
+import (
+	"math"
+	"time"
+
+	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+type Interactor interface {
+	Matrix(window *glfw.Window) fauxgl.Matrix
+	CursorPositionCallback(window *glfw.Window, x, y float64)
+	MouseButtonCallback(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey)
+	KeyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey)
+	ScrollCallback(window *glfw.Window, dx, dy float64)
+}
+
+// Animator is implemented by interactors that move the view on their own,
+// like WASD while a movement key is held, so the view must be redrawn
+// continuously.
+type Animator interface {
+	Animating(window *glfw.Window) bool
+}
+
+// Projector is implemented by interactors that can show the view with an
+// orthographic projection instead of a perspective one.
+type Projector interface {
+	SetOrtho(ortho bool)
+}
+
+// PickFunc returns the point of the model under the cursor, in the space the
+// interactor matrix is applied to, if there is one.
+type PickFunc func(window *glfw.Window) (fauxgl.Vector, bool)
+
+const doubleClickTime = 300 * time.Millisecond
+
+// isDoubleClick reports whether a click now follows the previous one closely
+// enough to make a double click, and records it.
+func isDoubleClick(previous *time.Time) bool {
+	now := time.Now()
+	double := now.Sub(*previous) < doubleClickTime
+	*previous = now
+	if double {
+		*previous = time.Time{}
+	}
+	return double
+}
+
+func BindInteractor(window *glfw.Window, interactor Interactor) {
+	window.SetCursorPosCallback(glfw.CursorPosCallback(interactor.CursorPositionCallback))
+	window.SetMouseButtonCallback(glfw.MouseButtonCallback(interactor.MouseButtonCallback))
+	window.SetKeyCallback(glfw.KeyCallback(interactor.KeyCallback))
+	window.SetScrollCallback(glfw.ScrollCallback(interactor.ScrollCallback))
+}
+
+// SwitchableInteractor switches between interactors with the Tab key. It
+// owns the projection, switched with the P key, so that it stays the same
+// when switching.
+type SwitchableInteractor struct {
+	Interactors []Interactor
+	Index       int
+	Ortho       bool
+}
+
+func NewSwitchableInteractor(interactors []Interactor) *SwitchableInteractor {
+	return &SwitchableInteractor{interactors, 0, false}
+}
+
+func (si *SwitchableInteractor) Switch() {
+	si.Index = (si.Index + 1) % len(si.Interactors)
+}
+
+// SetOrtho sets the projection of every interactor that implements
+// Projector.
+func (si *SwitchableInteractor) SetOrtho(ortho bool) {
+	si.Ortho = ortho
+	for _, interactor := range si.Interactors {
+		if projector, ok := interactor.(Projector); ok {
+			projector.SetOrtho(ortho)
+		}
+	}
+}
+
+func (si *SwitchableInteractor) Matrix(window *glfw.Window) fauxgl.Matrix {
+	return si.Interactors[si.Index].Matrix(window)
+}
+
+// Animating reports whether the current interactor is animating.
+func (si *SwitchableInteractor) Animating(window *glfw.Window) bool {
+	if animator, ok := si.Interactors[si.Index].(Animator); ok {
+		return animator.Animating(window)
+	}
+	return false
+}
+
+func (si *SwitchableInteractor) CursorPositionCallback(window *glfw.Window, x, y float64) {
+	si.Interactors[si.Index].CursorPositionCallback(window, x, y)
+}
+
+func (si *SwitchableInteractor) MouseButtonCallback(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	si.Interactors[si.Index].MouseButtonCallback(window, button, action, mods)
+}
+
+func (si *SwitchableInteractor) KeyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if key == glfw.KeyTab && action == glfw.Press {
+		si.Switch()
+	}
+	if key == glfw.KeyP && action == glfw.Press && mods == 0 {
+		si.SetOrtho(!si.Ortho)
+	}
+	si.Interactors[si.Index].KeyCallback(window, key, scancode, action, mods)
+}
+
+func (si *SwitchableInteractor) ScrollCallback(window *glfw.Window, dx, dy float64) {
+	si.Interactors[si.Index].ScrollCallback(window, dx, dy)
+}
+
+// Turntable
+
+// Turntable spins the model about the Z axis and tilts it towards or away
+// from the viewer, so that Z always stays up on screen. It pans and zooms
+// like Arcball.
+type Turntable struct {
+	Sensitivity float64
+	Yaw, Pitch  float64
+	Px, Py      float64
+	Start       fauxgl.Vector
+	Current     fauxgl.Vector
+	Translation fauxgl.Vector
+	Pivot       fauxgl.Vector
+	Scroll      float64
+	Rotate      bool
+	Pan         bool
+	Ortho       bool
+	Pick        PickFunc
+	clicked     time.Time
+}
+
+func NewTurntable() Interactor {
+	t := Turntable{}
+	t.Sensitivity = 0.5
+	return &t
+}
+
+func (t *Turntable) CursorPositionCallback(window *glfw.Window, x, y float64) {
+	if t.Rotate {
+		t.Yaw += fauxgl.Radians((x - t.Px) * t.Sensitivity)
+		t.Pitch += fauxgl.Radians((y - t.Py) * t.Sensitivity)
+		t.Pitch = math.Max(t.Pitch, -math.Pi/2)
+		t.Pitch = math.Min(t.Pitch, math.Pi/2)
+		t.Px = x
+		t.Py = y
+	}
+	if t.Pan {
+		t.Current = screenPosition(window)
+	}
+}
+
+func (t *Turntable) MouseButtonCallback(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	if button == glfw.MouseButton1 {
+		if action == glfw.Press {
+			if mods == 0 {
+				if isDoubleClick(&t.clicked) && t.Pick != nil {
+					if p, ok := t.Pick(window); ok {
+						r := turntableRotation(t.Yaw, t.Pitch)
+						t.Translation = movePivot(r, t.Pivot, t.Translation, p, t.Scroll)
+						t.Pivot = p
+					}
+				}
+				t.Rotate = true
+				t.Px, t.Py = window.GetCursorPos()
+			} else {
+				v := screenPosition(window)
+				t.Start = v
+				t.Current = v
+				t.Pan = true
+			}
+		} else if action == glfw.Release {
+			t.Rotate = false
+			if t.Pan {
+				d := t.Current.Sub(t.Start)
+				t.Translation = t.Translation.Add(d)
+				t.Pan = false
+			}
+		}
+	}
+}
+
+func (t *Turntable) ScrollCallback(window *glfw.Window, dx, dy float64) {
+	if t.Pick != nil && !t.Rotate && !t.Pan {
+		if p, ok := t.Pick(window); ok {
+			r := turntableRotation(t.Yaw, t.Pitch)
+			t.Translation = zoomToward(r, t.Pivot, t.Translation, p, t.Scroll, dy)
+		}
+	}
+	t.Scroll += dy
+}
+
+func (t *Turntable) KeyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if action == glfw.Press && mods == 0 {
+		if key >= glfw.Key1 && key <= glfw.Key7 {
+			t.Yaw, t.Pitch = turntablePreset(int(key - glfw.Key0))
+			t.Translation = fauxgl.Vector{}
+			t.Pivot = fauxgl.Vector{}
+			t.Scroll = 0
+		}
+	}
+}
+
+func (t *Turntable) SetOrtho(ortho bool) {
+	t.Ortho = ortho
+}
+
+func (t *Turntable) Matrix(window *glfw.Window) fauxgl.Matrix {
+	w, h := window.GetFramebufferSize()
+	aspect := float64(w) / float64(h)
+	tr := t.Translation
+	if t.Pan {
+		tr = tr.Add(t.Current.Sub(t.Start))
+	}
+	return meshview.Camera(turntableRotation(t.Yaw, t.Pitch), t.Pivot, tr, t.Scroll, aspect, t.Ortho)
+}
+
+// turntableRotation spins about Z first, so that tilting happens about the
+// horizontal screen axis.
+func turntableRotation(yaw, pitch float64) fauxgl.Matrix {
+	m := fauxgl.Identity()
+	m = m.Rotate(fauxgl.V(0, 0, 1), yaw)
+	m = m.Rotate(fauxgl.V(1, 0, 0), pitch)
+	return m
+}
+
+// turntablePreset returns the yaw and pitch for the numbered preset views.
+// Views 1 through 6 match meshview.PresetView; view 7, which rolls the model in
+// Arcball, is a corner view tilted like view 5 instead.
+func turntablePreset(view int) (float64, float64) {
+	switch view {
+	case 2:
+		return math.Pi / 2, 0
+	case 3:
+		return math.Pi, 0
+	case 4:
+		return -math.Pi / 2, 0
+	case 5:
+		return 0, math.Pi / 2
+	case 6:
+		return 0, -math.Pi / 2
+	case 7:
+		return math.Pi / 4, math.Pi / 4
+	}
+	return 0, 0
+}
+
+// Arcball
@@ -0,0 +1,97 @@
+package viewer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// measureColor must be visible against both the background and every color
+// in objectColors
+var measureColor = fauxgl.HexColor("D6336C")
+
+// measurement collects points picked on the model, in model units, and
+// reports the distance between the first two and the angle made by three.
+type measurement struct {
+	Points []fauxgl.Vector
+	buffer uint32
+}
+
+// add adds a point, starting over after an angle, and returns the new
+// result, if any.
+func (m *measurement) add(p fauxgl.Vector) string {
+	if len(m.Points) == 3 {
+		m.Points = m.Points[:0]
+	}
+	m.Points = append(m.Points, p)
+	return m.result()
+}
+
+func (m *measurement) clear() {
+	m.Points = m.Points[:0]
+}
+
+func (m *measurement) result() string {
+	switch len(m.Points) {
+	case 2:
+		d := m.Points[1].Sub(m.Points[0]).Length()
+		return fmt.Sprintf("distance %.6g", d)
+	case 3:
+		u := m.Points[0].Sub(m.Points[1])
+		v := m.Points[2].Sub(m.Points[1])
+		if u.Length() == 0 || v.Length() == 0 {
+			return "angle undefined"
+		}
+		cos := math.Max(-1, math.Min(1, u.Normalize().Dot(v.Normalize())))
+		return fmt.Sprintf("angle %.6g degrees", fauxgl.Degrees(math.Acos(cos)))
+	}
+	return ""
+}
+
+// draw draws the picked points joined by lines, over the model.
+func (m *measurement) draw(positionAttrib uint32) {
+	if len(m.Points) == 0 {
+		return
+	}
+	if m.buffer == 0 {
+		gl.GenBuffers(1, &m.buffer)
+	}
+	data := make([]float32, 0, len(m.Points)*3)
+	for _, p := range m.Points {
+		data = append(data, float32(p.X), float32(p.Y), float32(p.Z))
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.buffer)
+	gl.BufferData(gl.ARRAY_BUFFER, len(data)*4, gl.Ptr(data), gl.DYNAMIC_DRAW)
+	gl.EnableVertexAttribArray(positionAttrib)
+	gl.VertexAttribPointer(positionAttrib, 3, gl.FLOAT, false, 12, gl.PtrOffset(0))
+	gl.Disable(gl.DEPTH_TEST)
+	gl.LineWidth(2)
+	gl.PointSize(6)
+	gl.DrawArrays(gl.LINE_STRIP, 0, int32(len(m.Points)))
+	gl.DrawArrays(gl.POINTS, 0, int32(len(m.Points)))
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DisableVertexAttribArray(positionAttrib)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// snapPoint returns the corner or edge midpoint of the hit triangle nearest
+// to the hit point.
+func snapPoint(data *meshview.MeshData, hit meshview.RayHit, p fauxgl.Vector) fauxgl.Vector {
+	p1, p2, p3 := data.Triangle(hit.Triangle)
+	candidates := []fauxgl.Vector{
+		p1, p2, p3,
+		p1.Add(p2).MulScalar(0.5),
+		p2.Add(p3).MulScalar(0.5),
+		p3.Add(p1).MulScalar(0.5),
+	}
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.Sub(p).Length() < best.Sub(p).Length() {
+			best = c
+		}
+	}
+	return best
+}
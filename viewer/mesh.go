@@ -0,0 +1,82 @@
+package viewer
+
+import (
+	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+type Mesh struct {
+	Box          fauxgl.Box
+	VertexBuffer uint32
+	NormalBuffer uint32
+	IndexBuffer  uint32
+	VertexCount  int32
+	IndexCount   int32
+}
+
+func NewMesh(data *meshview.MeshData) *Mesh {
+	// generate vbo
+	var vbo uint32
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(data.Buffer)*4, gl.Ptr(data.Buffer), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// generate normal vbo if there are normals
+	var nbo uint32
+	if len(data.Normals) > 0 {
+		gl.GenBuffers(1, &nbo)
+		gl.BindBuffer(gl.ARRAY_BUFFER, nbo)
+		gl.BufferData(gl.ARRAY_BUFFER, len(data.Normals)*4, gl.Ptr(data.Normals), gl.STATIC_DRAW)
+		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	}
+
+	// generate ibo for indexed data
+	var ibo uint32
+	if len(data.Indices) > 0 {
+		gl.GenBuffers(1, &ibo)
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ibo)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(data.Indices)*4, gl.Ptr(data.Indices), gl.STATIC_DRAW)
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+	}
+
+	// compute number of vertices and indices
+	vertexCount := int32(len(data.Buffer) / 3)
+	indexCount := int32(len(data.Indices))
+
+	return &Mesh{data.Box, vbo, nbo, ibo, vertexCount, indexCount}
+}
+
+func (mesh *Mesh) Draw(positionAttrib, normalAttrib uint32) {
+	if mesh.NormalBuffer != 0 {
+		gl.BindBuffer(gl.ARRAY_BUFFER, mesh.NormalBuffer)
+		gl.EnableVertexAttribArray(normalAttrib)
+		gl.VertexAttribPointer(normalAttrib, 3, gl.FLOAT, false, 12, gl.PtrOffset(0))
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.VertexBuffer)
+	gl.EnableVertexAttribArray(positionAttrib)
+	gl.VertexAttribPointer(positionAttrib, 3, gl.FLOAT, false, 12, gl.PtrOffset(0))
+	if mesh.IndexBuffer != 0 {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, mesh.IndexBuffer)
+		gl.DrawElements(gl.TRIANGLES, mesh.IndexCount, gl.UNSIGNED_INT, gl.PtrOffset(0))
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+	} else {
+		gl.DrawArrays(gl.TRIANGLES, 0, mesh.VertexCount)
+	}
+	gl.DisableVertexAttribArray(positionAttrib)
+	if mesh.NormalBuffer != 0 {
+		gl.DisableVertexAttribArray(normalAttrib)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+func (mesh *Mesh) Destroy() {
+	gl.DeleteBuffers(1, &mesh.VertexBuffer)
+	if mesh.NormalBuffer != 0 {
+		gl.DeleteBuffers(1, &mesh.NormalBuffer)
+	}
+	if mesh.IndexBuffer != 0 {
+		gl.DeleteBuffers(1, &mesh.IndexBuffer)
+	}
+}
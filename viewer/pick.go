@@ -0,0 +1,25 @@
+package viewer
+
+import (
+	"github.com/fogleman/fauxgl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// CursorRay returns the ray through the cursor in the space that matrix
+// projects to the screen. For a mesh drawn with an interactor, matrix is the
+// interactor matrix times the mesh transform, and the ray is in model space.
+func CursorRay(window *glfw.Window, matrix fauxgl.Matrix) (fauxgl.Vector, fauxgl.Vector) {
+	x, y := window.GetCursorPos()
+	w, h := window.GetSize()
+	x = (x/float64(w))*2 - 1
+	y = 1 - (y/float64(h))*2
+	inverse := matrix.Inverse()
+	near := unproject(inverse, x, y, -1)
+	far := unproject(inverse, x, y, 1)
+	return near, far.Sub(near).Normalize()
+}
+
+func unproject(inverse fauxgl.Matrix, x, y, z float64) fauxgl.Vector {
+	v := inverse.MulPositionW(fauxgl.V(x, y, z))
+	return fauxgl.V(v.X/v.W, v.Y/v.W, v.Z/v.W)
+}
@@ -0,0 +1,796 @@
+package viewer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	imagedraw "image/draw"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+var vertexShader = `
+#version 120
+
+uniform mat4 matrix;
+uniform mat4 tile;
+uniform mat3 normal_matrix;
+
+attribute vec4 position;
+attribute vec3 normal;
+
+varying vec3 ec_pos;
+varying vec3 ec_normal;
+
+void main() {
+	vec4 clip_pos = matrix * position;
+	gl_Position = tile * clip_pos;
+	ec_pos = vec3(clip_pos);
+	ec_normal = normal_matrix * normal;
+}
+`
+
+var fragmentShader = `
+#version 120
+
+uniform bool smooth_shading;
+uniform bool unlit;
+uniform vec3 object_color;
+
+varying vec3 ec_pos;
+varying vec3 ec_normal;
+
+const vec3 light_direction = normalize(vec3(1, -1.5, 1));
+
+void main() {
+	if (unlit) {
+		gl_FragColor = vec4(object_color, 1);
+		return;
+	}
+	vec3 normal;
+	if (smooth_shading) {
+		normal = normalize(ec_normal);
+	} else {
+		normal = normalize(cross(dFdx(ec_pos), dFdy(ec_pos)));
+	}
+	float diffuse = max(0, dot(normal, light_direction)) * 0.9 + 0.15;
+	vec3 color = object_color * diffuse;
+	gl_FragColor = vec4(color, 1);
+}
+`
+
+// light_direction in fragmentShader must match meshview.LightDirection
+
+// edgeColor is used for edges drawn over or instead of shaded faces
+var edgeColor = fauxgl.HexColor("2E3A45")
+
+// display modes, cycled with the E key
+const (
+	displayShaded = iota
+	displayWireframe
+	displayShadedEdges
+	displayHiddenLine
+	displayModeCount
+)
+
+var displayModeNames = []string{"shaded", "wireframe", "shaded with edges", "hidden line"}
+
+func init() {
+	runtime.LockOSThread()
+}
+
+// the main loop waits for events, so goroutines sending it results wake it
+// up once glfw is running
+var (
+	wakeMutex   sync.RWMutex
+	wakeEnabled bool
+)
+
+func wake() {
+	wakeMutex.RLock()
+	if wakeEnabled {
+		glfw.PostEmptyEvent()
+	}
+	wakeMutex.RUnlock()
+}
+
+func setWakeEnabled(enabled bool) {
+	wakeMutex.Lock()
+	wakeEnabled = enabled
+	wakeMutex.Unlock()
+}
+
+// loadResult is the outcome of a load, which is stale if its context has
+// been cancelled by a later load of the same path.
+type loadResult struct {
+	Path    string
+	Data    *meshview.MeshData
+	Err     error
+	Context context.Context
+}
+
+func loadMesh(ctx context.Context, path string, options *meshview.LoadOptions, ch chan loadResult) {
+	go func() {
+		start := time.Now()
+		data, err := meshview.LoadMeshContext(ctx, path, options)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			ch <- loadResult{path, nil, fmt.Errorf("failed to load %s: %v", path, err), ctx}
+			wake()
+			return
+		}
+		fmt.Printf(
+			"loaded %d triangles in %.3f seconds\n",
+			data.TriangleCount(), time.Since(start).Seconds())
+		ch <- loadResult{path, data, nil, ctx}
+		wake()
+	}()
+}
+
+// normalsResult holds a copy of Data with vertex normals, for drawing with
+// smooth shading.
+type normalsResult struct {
+	Data   *meshview.MeshData
+	Smooth *meshview.MeshData
+}
+
+// computeNormals computes vertex normals for a copy of data, so that data
+// keeps the positions as loaded.
+func computeNormals(data *meshview.MeshData, creaseAngle float64, ch chan normalsResult) {
+	go func() {
+		start := time.Now()
+		smooth := *data
+		smooth.ComputeNormals(creaseAngle)
+		fmt.Printf("computed normals in %.3f seconds\n", time.Since(start).Seconds())
+		ch <- normalsResult{data, &smooth}
+		wake()
+	}()
+}
+
+type bvhResult struct {
+	Data *meshview.MeshData
+	BVH  *meshview.BVH
+}
+
+func buildBVH(data *meshview.MeshData, ch chan bvhResult) {
+	go func() {
+		start := time.Now()
+		bvh := meshview.NewBVH(data)
+		fmt.Printf("built bvh in %.3f seconds\n", time.Since(start).Seconds())
+		ch <- bvhResult{data, bvh}
+		wake()
+	}()
+}
+
+// Options configures the viewer. DefaultOptions returns the defaults.
+type Options struct {
+	// Load is used for every mesh the viewer loads.
+	Load meshview.LoadOptions
+
+	// CreaseAngle is the angle in degrees between adjacent faces above which
+	// the edge between them stays sharp with smooth shading.
+	CreaseAngle float64
+
+	// WatchDirectory lists a browsed directory or glob again when files are
+	// added to or removed from it.
+	WatchDirectory bool
+
+	// ScreenshotDir is where screenshots are saved. When empty, they are
+	// saved next to the selected model, or in the current directory.
+	ScreenshotDir string
+
+	// ScreenshotScale captures screenshots at this many times the window
+	// size, rendering the view in tiles.
+	ScreenshotScale int
+
+	// ScreenshotTransparent leaves the background of screenshots
+	// transparent.
+	ScreenshotTransparent bool
+}
+
+// DefaultOptions returns the options Run uses when given nil, which print
+// skipped records to stderr.
+func DefaultOptions() *Options {
+	return &Options{
+		Load:            meshview.LoadOptions{Warnings: os.Stderr},
+		CreaseAngle:     meshview.DefaultCreaseAngle,
+		ScreenshotScale: 1,
+	}
+}
+
+// Run opens a window showing the meshes at paths, which may be empty to start
+// with an empty window, using the given options, or DefaultOptions if options
+// is nil. Each mesh is drawn in its own color and all of them are framed
+// together. Run returns an error if any of the meshes at paths cannot be
+// loaded; later failures, from dropped or modified files, are reported in the
+// window title and leave the current meshes in place.
+func Run(options *Options, paths ...string) error {
+	start := time.Now()
+	if options == nil {
+		options = DefaultOptions()
+	}
+
+	// results are buffered so that senders can wake the main loop after
+	// sending them
+	ch := make(chan loadResult, 1)
+	normals := make(chan normalsResult, 1)
+	bvhs := make(chan bvhResult, 1)
+
+	// watch for file changes
+	watcher, err := newFileWatcher()
+	if err != nil {
+		panic(err)
+	}
+	defer watcher.Close()
+	events := make(chan fsnotify.Event, 1)
+	go func() {
+		for event := range watcher.Events {
+			events <- event
+			wake()
+		}
+		close(events)
+	}()
+	go func() {
+		for range watcher.Errors {
+		}
+	}()
+
+	// entries of a zip archive are reloaded when the archive changes
+	watched := make(map[string]bool)
+	watch := func(path string) {
+		if path == "-" || watched[path] {
+			return
+		}
+		archive, _ := meshview.SplitZipPath(path)
+		if err := watcher.add(archive); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to watch %s: %v\n", archive, err)
+			return
+		}
+		watched[path] = true
+	}
+	unwatchAll := func() {
+		for path := range watched {
+			archive, _ := meshview.SplitZipPath(path)
+			watcher.remove(archive)
+			delete(watched, path)
+		}
+	}
+
+	// a new load of a path cancels the one in progress, so that a slow,
+	// stale load cannot finish last and replace a newer mesh
+	loads := make(map[string]context.CancelFunc)
+	load := func(path string) {
+		if cancel, ok := loads[path]; ok {
+			cancel()
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		loads[path] = cancel
+		loadMesh(ctx, path, &options.Load, ch)
+	}
+	cancelLoads := func() {
+		for path, cancel := range loads {
+			cancel()
+			delete(loads, path)
+		}
+	}
+
+	// each file is reloaded once it has not changed for a moment. the timers
+	// hand the path back to the main loop, which owns the loads.
+	reloads := make(chan string, 1)
+	watchTimers := make(map[string]*time.Timer)
+	reload := func(path string) {
+		if timer, ok := watchTimers[path]; ok {
+			timer.Stop()
+		}
+		watchTimers[path] = time.AfterFunc(200*time.Millisecond, func() {
+			reloads <- path
+			wake()
+		})
+	}
+	stopReloads := func() {
+		for path, timer := range watchTimers {
+			timer.Stop()
+			delete(watchTimers, path)
+		}
+	}
+
+	// a single directory or glob is browsed one file at a time, starting
+	// with the first
+	var browse *browser
+	if len(paths) == 1 && isBrowsePattern(paths[0]) {
+		browse, err = newBrowser(paths[0])
+		if err != nil {
+			return err
+		}
+		if options.WatchDirectory {
+			if dir := browse.dir(); dir == "" {
+				fmt.Fprintf(os.Stderr, "cannot watch %s for new files\n", browse.Pattern)
+			} else if err := watcher.addDir(dir); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to watch %s: %v\n", dir, err)
+			}
+		}
+		paths = []string{browse.current()}
+	}
+
+	// load meshes in the background. browsed files may fail to load without
+	// ending the viewer.
+	initialPaths := make(map[string]bool)
+	for _, path := range paths {
+		if browse == nil {
+			initialPaths[path] = true
+		}
+		load(path)
+		watch(path)
+	}
+
+	// initialize glfw
+	if err := glfw.Init(); err != nil {
+		panic(err)
+	}
+	defer glfw.Terminate()
+	setWakeEnabled(true)
+	defer setWakeEnabled(false)
+
+	// loads that finished before waking was enabled could not wake the main
+	// loop, so make sure it checks for their results
+	glfw.PostEmptyEvent()
+
+	// create the window
+	glfw.WindowHint(glfw.Samples, 4)
+	glfw.WindowHint(glfw.ContextVersionMajor, 2)
+	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	window, err := glfw.CreateWindow(640, 640, strings.Join(paths, ", "), nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	window.MakeContextCurrent()
+
+	fmt.Printf("window shown at %.3f seconds\n", time.Since(start).Seconds())
+
+	// initialize gl
+	if err := gl.Init(); err != nil {
+		panic(err)
+	}
+
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Enable(gl.CULL_FACE)
+	gl.CullFace(gl.BACK)
+	gl.ClearColor(float32(meshview.BackgroundColor.R), float32(meshview.BackgroundColor.G), float32(meshview.BackgroundColor.B), 1)
+
+	// compile shaders
+	program, err := compileProgram(vertexShader, fragmentShader)
+	if err != nil {
+		panic(err)
+	}
+	gl.UseProgram(program)
+
+	matrixUniform := uniformLocation(program, "matrix")
+	tileUniform := uniformLocation(program, "tile")
+	normalMatrixUniform := uniformLocation(program, "normal_matrix")
+	smoothUniform := uniformLocation(program, "smooth_shading")
+	colorUniform := uniformLocation(program, "object_color")
+	unlitUniform := uniformLocation(program, "unlit")
+	positionAttrib := attribLocation(program, "position")
+	normalAttrib := attribLocation(program, "normal")
+
+	var objects scene
+	var measure measurement
+	smooth := false
+	measuring := false
+	displayMode := displayShaded
+	redraw := true
+	screenshot := false
+
+	title := func() string {
+		if browse != nil {
+			return browse.title()
+		}
+		return objects.title()
+	}
+
+	// clearScene removes every object, abandoning loads and reloads in
+	// progress
+	clearScene := func() {
+		unwatchAll()
+		stopReloads()
+		objects.clear()
+		cancelLoads()
+		initialPaths = make(map[string]bool)
+		redraw = true
+	}
+
+	// smoothObjects computes normals for the objects that have none, the
+	// first time they are shown with smooth shading
+	smoothObjects := func() {
+		for _, object := range objects.Objects {
+			if object.Mesh.NormalBuffer == 0 && !object.Smoothing {
+				object.Smoothing = true
+				computeNormals(object.Data, options.CreaseAngle, normals)
+			}
+		}
+	}
+
+	// show replaces the scene with the browsed file at the new position
+	show := func(path string) {
+		clearScene()
+		load(path)
+		watch(path)
+		window.SetTitle(title())
+	}
+
+	// create interactor
+	arcball := NewArcball().(*Arcball)
+	turntable := NewTurntable().(*Turntable)
+	interactor := NewSwitchableInteractor([]Interactor{
+		arcball,
+		NewWASD(nil),
+		turntable,
+	})
+	BindInteractor(window, interactor)
+
+	// find the point on the scene under the cursor, in model space
+	pickModel := func(window *glfw.Window) (*sceneObject, meshview.RayHit, fauxgl.Vector, bool) {
+		if len(objects.Objects) == 0 {
+			return nil, meshview.RayHit{}, fauxgl.Vector{}, false
+		}
+		transform := objects.transform()
+		origin, direction := CursorRay(window, getMatrix(window, interactor, transform))
+		object, hit, ok := objects.raycast(origin, direction)
+		if !ok {
+			return nil, meshview.RayHit{}, fauxgl.Vector{}, false
+		}
+		return object, hit, origin.Add(direction.MulScalar(hit.Distance)), true
+	}
+
+	// interactors zoom toward and orbit around picked points
+	pick := func(window *glfw.Window) (fauxgl.Vector, bool) {
+		_, _, point, ok := pickModel(window)
+		if !ok {
+			return fauxgl.Vector{}, false
+		}
+		return objects.transform().MulPosition(point), true
+	}
+	arcball.Pick = pick
+	turntable.Pick = pick
+
+	// handle viewer keys, passing everything on to the interactor
+	window.SetKeyCallback(func(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if action == glfw.Press && mods == 0 {
+			switch key {
+			case glfw.KeyN:
+				smooth = !smooth
+				if smooth {
+					smoothObjects()
+				}
+			case glfw.KeyO:
+				objects.cycle(1)
+				window.SetTitle(title())
+			case glfw.KeyH:
+				objects.toggleVisible()
+				window.SetTitle(title())
+			case glfw.KeyI:
+				objects.isolate()
+				window.SetTitle(title())
+			case glfw.KeyC:
+				objects.recolor()
+			case glfw.KeyE:
+				displayMode = (displayMode + 1) % displayModeCount
+				fmt.Println("display:", displayModeNames[displayMode])
+			case glfw.KeyPageDown, glfw.KeyRightBracket:
+				if browse != nil && len(browse.Files) > 1 {
+					show(browse.step(1))
+				}
+			case glfw.KeyPageUp, glfw.KeyLeftBracket:
+				if browse != nil && len(browse.Files) > 1 {
+					show(browse.step(-1))
+				}
+			case glfw.KeyF12:
+				screenshot = true
+			case glfw.KeyM:
+				measuring = !measuring
+				measure.clear()
+				if measuring {
+					window.SetTitle("measure: click points, hold shift to snap")
+				} else {
+					window.SetTitle(title())
+				}
+			}
+		} else if action == glfw.Press && mods == glfw.ModShift && key == glfw.KeyO {
+			objects.cycle(-1)
+			window.SetTitle(title())
+		}
+		interactor.KeyCallback(window, key, scancode, action, mods)
+		redraw = true
+	})
+
+	// in measure mode, clicks pick points instead of moving the camera
+	window.SetMouseButtonCallback(func(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		redraw = true
+		if !measuring || button != glfw.MouseButton1 {
+			interactor.MouseButtonCallback(window, button, action, mods)
+			return
+		}
+		if action != glfw.Press {
+			return
+		}
+		object, hit, point, ok := pickModel(window)
+		if !ok {
+			return
+		}
+		if mods&glfw.ModShift != 0 {
+			point = snapPoint(object.Data, hit, point)
+		}
+		fmt.Printf("point %.6g %.6g %.6g\n", point.X, point.Y, point.Z)
+		if result := measure.add(point); result != "" {
+			fmt.Println(result)
+			window.SetTitle("measure: " + result)
+		}
+	})
+
+	// draw function, which draws the part of the view that tile maps to the
+	// window
+	draw := func(tile fauxgl.Matrix) {
+		gl.Clear(gl.DEPTH_BUFFER_BIT | gl.COLOR_BUFFER_BIT)
+		setMatrix(tileUniform, tile)
+		if len(objects.Objects) > 0 {
+			matrix := getMatrix(window, interactor, objects.transform())
+			setMatrix(matrixUniform, matrix)
+			setNormalMatrix(normalMatrixUniform, matrix)
+
+			// faces are pushed back so that edges drawn over them pass the
+			// depth test. hidden line mode draws them into the depth buffer
+			// only, to hide the edges behind them.
+			fill := displayMode != displayWireframe
+			edges := displayMode != displayShaded
+			if fill {
+				if edges {
+					gl.Enable(gl.POLYGON_OFFSET_FILL)
+					gl.PolygonOffset(1, 1)
+				}
+				if displayMode == displayHiddenLine {
+					gl.ColorMask(false, false, false, false)
+				}
+				for _, object := range objects.Objects {
+					if !object.Visible {
+						continue
+					}
+					setBool(smoothUniform, smooth && object.Mesh.NormalBuffer != 0)
+					setColor(colorUniform, objectColors[object.Color])
+					object.Mesh.Draw(positionAttrib, normalAttrib)
+				}
+				gl.ColorMask(true, true, true, true)
+				gl.Disable(gl.POLYGON_OFFSET_FILL)
+			}
+
+			// wireframe mode shows back edges too, in the object color
+			if edges {
+				gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+				if !fill {
+					gl.Disable(gl.CULL_FACE)
+				}
+				setBool(unlitUniform, true)
+				for _, object := range objects.Objects {
+					if !object.Visible {
+						continue
+					}
+					if fill {
+						setColor(colorUniform, edgeColor)
+					} else {
+						setColor(colorUniform, objectColors[object.Color])
+					}
+					object.Mesh.Draw(positionAttrib, normalAttrib)
+				}
+				setBool(unlitUniform, false)
+				gl.Enable(gl.CULL_FACE)
+				gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+			}
+
+			if measuring {
+				setBool(unlitUniform, true)
+				setColor(colorUniform, measureColor)
+				measure.draw(positionAttrib)
+				setBool(unlitUniform, false)
+			}
+		}
+	}
+
+	// render function
+	render := func() {
+		draw(fauxgl.Identity())
+		window.SwapBuffers()
+	}
+
+	// capture draws the view without showing it and reads it back, in
+	// options.ScreenshotScale by options.ScreenshotScale tiles the size of the
+	// window
+	capture := func() image.Image {
+		w, h := window.GetFramebufferSize()
+		k := options.ScreenshotScale
+		if k < 1 {
+			k = 1
+		}
+		if options.ScreenshotTransparent {
+			gl.ClearColor(0, 0, 0, 0)
+		}
+		gl.Viewport(0, 0, int32(w), int32(h))
+		im := image.NewRGBA(image.Rect(0, 0, w*k, h*k))
+		for j := 0; j < k; j++ {
+			for i := 0; i < k; i++ {
+				// scale the view up and move tile i, j from the top left
+				// to the middle
+				tx := float64(k - 1 - 2*i)
+				ty := float64(2*j - (k - 1))
+				s := float64(k)
+				tile := fauxgl.Translate(fauxgl.V(tx, ty, 0)).Mul(fauxgl.Scale(fauxgl.V(s, s, 1)))
+				draw(tile)
+				r := image.Rect(i*w, j*h, (i+1)*w, (j+1)*h)
+				imagedraw.Draw(im, r, readPixels(w, h), image.Point{}, imagedraw.Src)
+			}
+		}
+		gl.ClearColor(float32(meshview.BackgroundColor.R), float32(meshview.BackgroundColor.G), float32(meshview.BackgroundColor.B), 1)
+		return im
+	}
+
+	// saveScreenshot captures the view and writes it in the background
+	saveScreenshot := func() {
+		var modelPath string
+		if object := objects.selected(); object != nil {
+			modelPath = object.Path
+		}
+		path := screenshotPath(options.ScreenshotDir, modelPath, time.Now())
+		im := capture()
+		go func() {
+			path, err := savePNG(path, im)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to save screenshot: %v\n", err)
+				return
+			}
+			fmt.Println("saved", path)
+		}()
+	}
+
+	// moving the cursor only changes the view while dragging or looking
+	// around
+	window.SetCursorPosCallback(func(window *glfw.Window, x, y float64) {
+		interactor.CursorPositionCallback(window, x, y)
+		if window.GetMouseButton(glfw.MouseButton1) == glfw.Press ||
+			window.GetInputMode(glfw.CursorMode) == glfw.CursorDisabled {
+			redraw = true
+		}
+	})
+
+	window.SetScrollCallback(func(window *glfw.Window, dx, dy float64) {
+		interactor.ScrollCallback(window, dx, dy)
+		redraw = true
+	})
+
+	// render during resize and when the window needs repainting
+	window.SetFramebufferSizeCallback(func(window *glfw.Window, w, h int) {
+		render()
+	})
+	window.SetRefreshCallback(func(window *glfw.Window) {
+		render()
+	})
+
+	// handle drop events, adding to the scene unless shift is held
+	window.SetDropCallback(func(window *glfw.Window, filenames []string) {
+		if window.GetKey(glfw.KeyLeftShift) == glfw.Press || window.GetKey(glfw.KeyRightShift) == glfw.Press {
+			clearScene()
+			browse = nil
+		}
+		for _, path := range filenames {
+			load(path)
+			watch(path)
+		}
+		window.SetTitle(strings.Join(filenames, ", "))
+	})
+
+	// main loop, which only draws when something has changed and otherwise
+	// sleeps until there are events or results from other goroutines
+	for !window.ShouldClose() {
+		animating := interactor.Animating(window)
+		if redraw || animating {
+			render()
+			redraw = false
+		}
+		if screenshot {
+			saveScreenshot()
+			screenshot = false
+		}
+		if animating {
+			glfw.PollEvents()
+		} else {
+			glfw.WaitEvents()
+		}
+	results:
+		for {
+			select {
+			case result := <-ch:
+				if result.Context.Err() != nil {
+					break
+				}
+				loads[result.Path]()
+				delete(loads, result.Path)
+				if result.Err != nil {
+					if initialPaths[result.Path] {
+						return result.Err
+					}
+					fmt.Fprintln(os.Stderr, result.Err)
+					message := result.Err.Error()
+					if len(objects.Objects) > 0 || browse != nil {
+						message = title() + " - " + message
+					}
+					window.SetTitle(message)
+					break
+				}
+				delete(initialPaths, result.Path)
+				objects.set(result.Path, result.Data)
+				buildBVH(result.Data, bvhs)
+				if smooth {
+					smoothObjects()
+				}
+				window.SetTitle(title())
+				redraw = true
+				fmt.Printf("first frame at %.3f seconds\n", time.Since(start).Seconds())
+			case path := <-reloads:
+				// a timer may have fired just before the scene was cleared
+				if watched[path] {
+					load(path)
+				}
+			case result := <-normals:
+				// the object may have been reloaded or removed since
+				for _, object := range objects.Objects {
+					if object.Data == result.Data {
+						object.Mesh.Destroy()
+						object.Mesh = NewMesh(result.Smooth)
+						object.Smoothing = false
+						redraw = true
+					}
+				}
+			case result := <-bvhs:
+				// the object may have been reloaded or removed since
+				for _, object := range objects.Objects {
+					if object.Data == result.Data {
+						object.BVH = result.BVH
+					}
+				}
+			case event, ok := <-events:
+				if !ok {
+					return nil
+				}
+				if browse != nil && options.WatchDirectory && browse.affects(event) {
+					if err := browse.list(); err != nil {
+						fmt.Fprintln(os.Stderr, err)
+					}
+					window.SetTitle(title())
+				}
+				if changed, ok := watcher.changed(event); ok {
+					for path := range watched {
+						if archive, _ := meshview.SplitZipPath(path); filepath.Clean(archive) == changed {
+							reload(path)
+						}
+					}
+				}
+			default:
+				break results
+			}
+		}
+	}
+	return nil
+}
+
+func getMatrix(window *glfw.Window, interactor Interactor, transform fauxgl.Matrix) fauxgl.Matrix {
+	return interactor.Matrix(window).Mul(transform)
+}
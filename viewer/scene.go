@@ -0,0 +1,201 @@
+package viewer
+
+import (
+	"fmt"
+
+	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
+)
+
+// objectColors are given to the objects of a scene in the order they are
+// added
+var objectColors = []fauxgl.Color{
+	meshview.MeshColor,
+	fauxgl.HexColor("E38B5B"),
+	fauxgl.HexColor("7DC47A"),
+	fauxgl.HexColor("B98AD6"),
+	fauxgl.HexColor("E3C85B"),
+	fauxgl.HexColor("5BCFC4"),
+}
+
+// sceneObject is a mesh in the scene. Vertex normals are only computed for
+// Mesh once smooth shading is turned on, and Smoothing is set while they are
+// being computed.
+type sceneObject struct {
+	Path      string
+	Data      *meshview.MeshData
+	BVH       *meshview.BVH
+	Mesh      *Mesh
+	Color     int
+	Visible   bool
+	Smoothing bool
+}
+
+// scene holds the meshes shown by the viewer, each loaded from its own file
+// and framed together by their combined bounding box. Hidden objects still
+// count towards the box, so that hiding them does not move the camera.
+type scene struct {
+	Objects  []*sceneObject
+	Selected int
+	added    int
+}
+
+// raycast uses the object's BVH once it has been built, and tests every
+// triangle until then.
+func (object *sceneObject) raycast(origin, direction fauxgl.Vector) (meshview.RayHit, bool) {
+	if object.BVH != nil {
+		return object.BVH.Raycast(origin, direction)
+	}
+	return object.Data.Raycast(origin, direction)
+}
+
+func (s *scene) find(path string) *sceneObject {
+	for _, object := range s.Objects {
+		if object.Path == path {
+			return object
+		}
+	}
+	return nil
+}
+
+// set replaces the mesh of the object loaded from path, or adds a new object
+// with the next color if there is none.
+func (s *scene) set(path string, data *meshview.MeshData) *sceneObject {
+	mesh := NewMesh(data)
+	if object := s.find(path); object != nil {
+		object.Mesh.Destroy()
+		object.Data = data
+		object.BVH = nil
+		object.Mesh = mesh
+		object.Smoothing = false
+		return object
+	}
+	object := &sceneObject{path, data, nil, mesh, s.added % len(objectColors), true, false}
+	s.Objects = append(s.Objects, object)
+	s.added++
+	return object
+}
+
+func (s *scene) clear() {
+	for _, object := range s.Objects {
+		object.Mesh.Destroy()
+	}
+	s.Objects = nil
+	s.Selected = 0
+	s.added = 0
+}
+
+func (s *scene) selected() *sceneObject {
+	if s.Selected >= len(s.Objects) {
+		return nil
+	}
+	return s.Objects[s.Selected]
+}
+
+// cycle moves the selection by step objects, wrapping around.
+func (s *scene) cycle(step int) {
+	n := len(s.Objects)
+	if n == 0 {
+		return
+	}
+	s.Selected = ((s.Selected+step)%n + n) % n
+}
+
+func (s *scene) toggleVisible() {
+	if object := s.selected(); object != nil {
+		object.Visible = !object.Visible
+	}
+}
+
+// isolate shows only the selected object, or shows every object again if
+// the selected one is already the only one shown.
+func (s *scene) isolate() {
+	selected := s.selected()
+	if selected == nil {
+		return
+	}
+	isolated := selected.Visible
+	for _, object := range s.Objects {
+		if object != selected && object.Visible {
+			isolated = false
+		}
+	}
+	for _, object := range s.Objects {
+		object.Visible = isolated || object == selected
+	}
+}
+
+// recolor gives the selected object the next palette color that no other
+// object is using, or just the next color once they are all taken.
+func (s *scene) recolor() {
+	selected := s.selected()
+	if selected == nil {
+		return
+	}
+	used := make(map[int]bool)
+	for _, object := range s.Objects {
+		if object != selected {
+			used[object.Color] = true
+		}
+	}
+	n := len(objectColors)
+	color := (selected.Color + 1) % n
+	for i := 1; i <= n; i++ {
+		c := (selected.Color + i) % n
+		if !used[c] {
+			color = c
+			break
+		}
+	}
+	selected.Color = color
+}
+
+func (s *scene) box() fauxgl.Box {
+	box := s.Objects[0].Mesh.Box
+	for _, object := range s.Objects[1:] {
+		box = box.Extend(object.Mesh.Box)
+	}
+	return box
+}
+
+// transform scales and centers all of the objects together.
+func (s *scene) transform() fauxgl.Matrix {
+	if len(s.Objects) == 0 {
+		return fauxgl.Identity()
+	}
+	return meshview.MeshTransform(s.box())
+}
+
+// raycast returns the nearest visible object hit by the ray, and where.
+func (s *scene) raycast(origin, direction fauxgl.Vector) (*sceneObject, meshview.RayHit, bool) {
+	var nearest *sceneObject
+	var nearestHit meshview.RayHit
+	for _, object := range s.Objects {
+		if !object.Visible {
+			continue
+		}
+		hit, ok := object.raycast(origin, direction)
+		if ok && (nearest == nil || hit.Distance < nearestHit.Distance) {
+			nearest = object
+			nearestHit = hit
+		}
+	}
+	return nearest, nearestHit, nearest != nil
+}
+
+// title names the selected object, and its position in the scene if there
+// are others.
+func (s *scene) title() string {
+	selected := s.selected()
+	if selected == nil {
+		return ""
+	}
+	title := selected.Path
+	if len(s.Objects) > 1 {
+		title = fmt.Sprintf("%s (%d of %d)", title, s.Selected+1, len(s.Objects))
+	}
+	if !selected.Visible {
+		title += " - hidden"
+	}
+	return title
+}
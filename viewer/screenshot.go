@@ -0,0 +1,75 @@
+package viewer
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fogleman/meshview"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// screenshotPath returns a path in dir for a screenshot of the model at
+// modelPath, named after the model and the time. An empty dir means next to
+// the model, or the current directory.
+func screenshotPath(dir, modelPath string, now time.Time) string {
+	name := "meshview"
+	if modelPath != "" && modelPath != "-" {
+		if dir == "" {
+			archive, _ := meshview.SplitZipPath(modelPath)
+			dir = filepath.Dir(archive)
+		}
+		name = meshview.ModelName(modelPath)
+	}
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.png", name, now.Format("20060102-150405")))
+}
+
+// readPixels reads the back buffer into an image, top row first. The image
+// holds premultiplied colors, as the framebuffer does when it is cleared to
+// transparent black.
+func readPixels(width, height int) *image.RGBA {
+	buf := make([]uint8, width*height*4)
+	gl.ReadBuffer(gl.BACK)
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(buf))
+	im := image.NewRGBA(image.Rect(0, 0, width, height))
+	stride := width * 4
+	for y := 0; y < height; y++ {
+		copy(im.Pix[y*im.Stride:y*im.Stride+stride], buf[(height-1-y)*stride:])
+	}
+	return im
+}
+
+// savePNG writes im to a new file at path, or, if there is already a file
+// there, at path with a -2, -3 and so on suffix, and returns the path used.
+// Screenshots taken within the same second get the same name from
+// screenshotPath, so they would otherwise overwrite each other.
+func savePNG(path string, im image.Image) (string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	var file *os.File
+	var err error
+	for i := 1; ; i++ {
+		if i > 1 {
+			path = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(file, im); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
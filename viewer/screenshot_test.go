@@ -0,0 +1,43 @@
+package viewer
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScreenshotPath(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		dir, model, path string
+	}{
+		{"", "", "meshview-20200102-030405.png"},
+		{"", "-", "meshview-20200102-030405.png"},
+		{"", "models/part.stl.gz", "models/part-20200102-030405.png"},
+		{"", "models/parts.zip/dir/arm.obj", "models/arm-20200102-030405.png"},
+		{"shots", "models/part.stl", "shots/part-20200102-030405.png"},
+	}
+	for _, test := range tests {
+		path := screenshotPath(test.dir, filepath.FromSlash(test.model), now)
+		if path != filepath.FromSlash(test.path) {
+			t.Errorf("%s: got %s, want %s", test.model, path, test.path)
+		}
+	}
+}
+
+func TestSavePNG(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	im := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	path := filepath.Join(dir, "part.png")
+	for _, want := range []string{"part.png", "part-2.png", "part-3.png"} {
+		got, err := savePNG(path, im)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != filepath.Join(dir, want) {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package meshview
+package viewer
 
 import (
 	"fmt"
@@ -18,6 +18,37 @@ func setMatrix(location int32, m fauxgl.Matrix) {
 	gl.UniformMatrix4fv(location, 1, true, &data[0])
 }
 
+// setNormalMatrix sets the matrix that carries normals into the same space as
+// positions transformed by m. The cofactor matrix of the upper 3x3 is used,
+// rather than the inverse transpose, so that mirroring keeps normals facing
+// the same way as cross products of transformed edges.
+func setNormalMatrix(location int32, m fauxgl.Matrix) {
+	data := [9]float32{
+		float32(m.X11*m.X22 - m.X12*m.X21),
+		float32(m.X12*m.X20 - m.X10*m.X22),
+		float32(m.X10*m.X21 - m.X11*m.X20),
+		float32(m.X02*m.X21 - m.X01*m.X22),
+		float32(m.X00*m.X22 - m.X02*m.X20),
+		float32(m.X01*m.X20 - m.X00*m.X21),
+		float32(m.X01*m.X12 - m.X02*m.X11),
+		float32(m.X02*m.X10 - m.X00*m.X12),
+		float32(m.X00*m.X11 - m.X01*m.X10),
+	}
+	gl.UniformMatrix3fv(location, 1, true, &data[0])
+}
+
+func setBool(location int32, value bool) {
+	if value {
+		gl.Uniform1i(location, 1)
+	} else {
+		gl.Uniform1i(location, 0)
+	}
+}
+
+func setColor(location int32, c fauxgl.Color) {
+	gl.Uniform3f(location, float32(c.R), float32(c.G), float32(c.B))
+}
+
 func uniformLocation(program uint32, name string) int32 {
 	return gl.GetUniformLocation(program, gl.Str(name+"\x00"))
 }
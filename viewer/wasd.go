@@ -1,16 +1,19 @@
-package meshview
+package viewer
 
 import (
 	"math"
 	"time"
 
 	"github.com/fogleman/fauxgl"
+	"github.com/fogleman/meshview"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
 type WASD struct {
 	sensitivity float64
 	invert      bool
+	ortho       bool
+	moving      bool
 	discard     bool
 	previous    time.Time
 	position    fauxgl.Vector
@@ -85,10 +88,24 @@ func (wasd *WASD) strafe(window *glfw.Window) (int, int, int) {
 
 func (wasd *WASD) updatePosition(window *glfw.Window, dt float64) {
 	sx, sy, sz := wasd.strafe(window)
+
+	// frames are only drawn continuously while moving, so the time since
+	// the last one is not a movement step when starting to move
+	if !wasd.moving {
+		dt = 0
+	}
+	wasd.moving = sx != 0 || sy != 0 || sz != 0
+
 	mv := wasd.motionVector(sx, sy, sz, dt)
 	wasd.position = wasd.position.Add(mv.MulScalar(dt * 1))
 }
 
+// Animating reports whether a movement key is held.
+func (wasd *WASD) Animating(window *glfw.Window) bool {
+	sx, sy, sz := wasd.strafe(window)
+	return sx != 0 || sy != 0 || sz != 0
+}
+
 func (wasd *WASD) CursorPositionCallback(window *glfw.Window, x, y float64) {
 	if !wasd.isExclusive(window) {
 		return
@@ -135,6 +152,10 @@ func (wasd *WASD) KeyCallback(window *glfw.Window, key glfw.Key, scancode int, a
 	}
 }
 
+func (wasd *WASD) SetOrtho(ortho bool) {
+	wasd.ortho = ortho
+}
+
 func (wasd *WASD) ScrollCallback(window *glfw.Window, dx, dy float64) {
 }
 
@@ -152,6 +173,7 @@ func (wasd *WASD) Matrix(window *glfw.Window) fauxgl.Matrix {
 
 	m := fauxgl.Identity()
 	m = m.LookAt(eye, center, fauxgl.V(0, 0, 1))
-	m = m.Perspective(50, aspect, 0.01, 100)
+	// orthographic views keep the size things at the origin had
+	m = meshview.Projection(m, eye.Length(), aspect, 0.01, wasd.ortho)
 	return m
 }
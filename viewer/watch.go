@@ -0,0 +1,79 @@
+package viewer
+
+import (
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileWatcher watches files through their directories. Many editors and
+// exporters save by writing a temporary file and renaming it over the
+// original, which ends a watch on the file itself, but the directory sees
+// the new file arrive.
+type fileWatcher struct {
+	*fsnotify.Watcher
+	files map[string]int
+	dirs  map[string]int
+}
+
+func newFileWatcher() (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fileWatcher{watcher, make(map[string]int), make(map[string]int)}, nil
+}
+
+// add watches the file at path. Each call must be matched by a call to
+// remove to stop watching it.
+func (w *fileWatcher) add(path string) error {
+	path = filepath.Clean(path)
+	dir := filepath.Dir(path)
+	if w.dirs[dir] == 0 {
+		if err := w.Watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+	w.dirs[dir]++
+	w.files[path]++
+	return nil
+}
+
+// addDir watches the directory itself, for files being added or removed.
+func (w *fileWatcher) addDir(dir string) error {
+	dir = filepath.Clean(dir)
+	if w.dirs[dir] == 0 {
+		if err := w.Watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+	w.dirs[dir]++
+	return nil
+}
+
+func (w *fileWatcher) remove(path string) {
+	path = filepath.Clean(path)
+	dir := filepath.Dir(path)
+	if w.files[path] == 0 {
+		return
+	}
+	w.files[path]--
+	if w.files[path] == 0 {
+		delete(w.files, path)
+	}
+	w.dirs[dir]--
+	if w.dirs[dir] == 0 {
+		delete(w.dirs, dir)
+		w.Watcher.Remove(dir)
+	}
+}
+
+// changed returns the watched file that event wrote or created, if any. A
+// file that is removed or renamed away is kept until it comes back.
+func (w *fileWatcher) changed(event fsnotify.Event) (string, bool) {
+	if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+		return "", false
+	}
+	path := filepath.Clean(event.Name)
+	return path, w.files[path] > 0
+}
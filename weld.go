@@ -0,0 +1,102 @@
+package meshview
+
+import "math"
+
+// Weld converts triangle soup into indexed data, merging vertices that lie
+// within epsilon of each other. An epsilon of zero merges only identical
+// vertices. Any normals are discarded.
+func (data *MeshData) Weld(epsilon float64) {
+	vertices, indices := weldVertices(data.TriangleBuffer(), epsilon)
+	data.Buffer = vertices
+	data.Indices = indices
+	data.Normals = nil
+	if len(vertices) > 0 {
+		data.Box = boxForData(vertices)
+	}
+}
+
+// weldVertices merges vertices in a flat triangle buffer, returning the unique
+// vertex positions and one index per input vertex. Each merged vertex takes
+// the position of the first vertex seen at that location.
+func weldVertices(buffer []float32, epsilon float64) ([]float32, []uint32) {
+	if epsilon > 0 {
+		return weldVerticesEpsilon(buffer, epsilon)
+	}
+	type key [3]float32
+	lookup := make(map[key]uint32)
+	vertices := make([]float32, 0, len(buffer)/6)
+	indices := make([]uint32, len(buffer)/3)
+	for i := 0; i+2 < len(buffer); i += 3 {
+		k := key{buffer[i], buffer[i+1], buffer[i+2]}
+		index, ok := lookup[k]
+		if !ok {
+			index = uint32(len(vertices) / 3)
+			lookup[k] = index
+			vertices = append(vertices, k[:]...)
+		}
+		indices[i/3] = index
+	}
+	return vertices, indices
+}
+
+func weldVerticesEpsilon(buffer []float32, epsilon float64) ([]float32, []uint32) {
+	// bucket vertices into a grid of epsilon sized cells, so that any match
+	// lies in the same or a neighboring cell
+	type cell [3]int64
+	lookup := make(map[cell][]uint32)
+	vertices := make([]float32, 0, len(buffer)/6)
+	indices := make([]uint32, len(buffer)/3)
+	e2 := epsilon * epsilon
+	for i := 0; i+2 < len(buffer); i += 3 {
+		x, y, z := float64(buffer[i]), float64(buffer[i+1]), float64(buffer[i+2])
+		c := cell{
+			int64(math.Floor(x / epsilon)),
+			int64(math.Floor(y / epsilon)),
+			int64(math.Floor(z / epsilon)),
+		}
+		index, found := uint32(0), false
+	search:
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for dz := int64(-1); dz <= 1; dz++ {
+					for _, j := range lookup[cell{c[0] + dx, c[1] + dy, c[2] + dz}] {
+						ex := float64(vertices[j*3+0]) - x
+						ey := float64(vertices[j*3+1]) - y
+						ez := float64(vertices[j*3+2]) - z
+						if ex*ex+ey*ey+ez*ez <= e2 {
+							index, found = j, true
+							break search
+						}
+					}
+				}
+			}
+		}
+		if !found {
+			index = uint32(len(vertices) / 3)
+			lookup[c] = append(lookup[c], index)
+			vertices = append(vertices, buffer[i], buffer[i+1], buffer[i+2])
+		}
+		indices[i/3] = index
+	}
+	return vertices, indices
+}
+
+// compactVertices drops vertices that no index refers to, renumbering the
+// indices in place.
+func compactVertices(buffer []float32, indices []uint32) []float32 {
+	const unused = math.MaxUint32
+	remap := make([]uint32, len(buffer)/3)
+	for i := range remap {
+		remap[i] = unused
+	}
+	vertices := make([]float32, 0, len(buffer))
+	for i, index := range indices {
+		if remap[index] == unused {
+			remap[index] = uint32(len(vertices) / 3)
+			j := index * 3
+			vertices = append(vertices, buffer[j:j+3]...)
+		}
+		indices[i] = remap[index]
+	}
+	return vertices
+}
@@ -0,0 +1,87 @@
+package meshview
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeld(t *testing.T) {
+	tests := []struct {
+		name     string
+		buffer   []float32
+		epsilon  float64
+		vertices int
+	}{
+		{"empty", nil, 0, 0},
+		{"shared edge", []float32{
+			0, 0, 0, 1, 0, 0, 1, 1, 0,
+			0, 0, 0, 1, 1, 0, 0, 1, 0,
+		}, 0, 4},
+		{"near miss", []float32{
+			0, 0, 0, 1, 0, 0, 1, 1, 0,
+			0, 0, 0, 1.0005, 1, 0, 0, 1, 0,
+		}, 0, 5},
+		{"within epsilon", []float32{
+			0, 0, 0, 1, 0, 0, 1, 1, 0,
+			0, 0, 0, 1.0005, 1, 0, 0, 1, 0,
+		}, 0.001, 4},
+		{"outside epsilon", []float32{
+			0, 0, 0, 1, 0, 0, 1, 1, 0,
+			0, 0, 0, 1.002, 1, 0, 0, 1, 0,
+		}, 0.001, 5},
+		{"across cells", []float32{
+			0.0999, 0, 0, 1, 0, 0, 1, 1, 0,
+			0.1001, 0, 0, 1, 1, 0, 0, 1, 0,
+		}, 0.001, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := &MeshData{Buffer: append([]float32(nil), test.buffer...)}
+			data.Weld(test.epsilon)
+			if n := len(data.Buffer) / 3; n != test.vertices {
+				t.Errorf("got %d vertices, want %d", n, test.vertices)
+			}
+			if len(data.Indices) != len(test.buffer)/3 {
+				t.Fatalf("got %d indices, want %d", len(data.Indices), len(test.buffer)/3)
+			}
+			for i, index := range data.Indices {
+				if int(index) >= test.vertices {
+					t.Fatalf("index %d out of range", index)
+				}
+				for j := 0; j < 3; j++ {
+					d := float64(data.Buffer[int(index)*3+j] - test.buffer[i*3+j])
+					if math.Abs(d) > test.epsilon {
+						t.Errorf("vertex %d moved by %g", i, d)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMeshWeld(t *testing.T) {
+	dir, cleanup := tempFiles(t, "quad.stl", stlASCIIQuad)
+	defer cleanup()
+	tests := []struct {
+		epsilon  float64
+		vertices int
+		indexed  bool
+	}{
+		{0, 4, true},
+		{0.01, 4, true},
+		{-1, 6, false},
+	}
+	for _, test := range tests {
+		data, err := LoadMeshOptions(filepath.Join(dir, "quad.stl"), &LoadOptions{WeldEpsilon: test.epsilon})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if indexed := data.Indices != nil; indexed != test.indexed {
+			t.Errorf("weld %g: got indexed %v, want %v", test.epsilon, indexed, test.indexed)
+		}
+		if n := len(data.Buffer) / 3; n != test.vertices {
+			t.Errorf("weld %g: got %d vertices, want %d", test.epsilon, n, test.vertices)
+		}
+	}
+}